@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"time"
@@ -20,24 +20,29 @@ func main() {
 	cfgOpt := getConfigOptions()
 	cfg, err := dependency.NewConfig(cfgOpt)
 	if err != nil {
-		log.Fatalf("failed to load configuration: %v", err)
+		fatal("failed to load configuration", err)
 	}
 
 	// setup database connections
 	// Setup database connection
 	db, err := dependency.NewDatabaseConfig(cfg)
 	if err != nil {
-		log.Fatalf("failed to create connection for database: %v", err)
+		fatal("failed to create connection for database", err)
 	}
 	err = db.Ping(context.Background())
 	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+		fatal("failed to connect to database", err)
 	}
 	defer db.Close()
 	// initialize the dependencies
 	api, err := dependency.NewWeCredit(cfg, db)
 	if err != nil {
-		log.Fatalf("failed to create weCredit instance: %v", err)
+		fatal("failed to create weCredit instance", err)
+	}
+	// create the first admin when configured
+	err = api.BootstrapAdmin()
+	if err != nil {
+		fatal("failed to bootstrap the first admin", err)
 	}
 	// setup echo framework
 	e := echo.New()
@@ -55,14 +60,20 @@ func main() {
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt)
 	<-quit
-	log.Println("Server is shuting down...")
+	slog.Info("Server is shuting down...")
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	if err := e.Shutdown(ctx); err != nil {
 		e.Logger.Fatal(err)
 	}
-	log.Println("Server successFully shut down")
+	slog.Info("Server successFully shut down")
+
+}
 
+// fatal logs the error and stops the application
+func fatal(msg string, err error) {
+	slog.Error(msg, "err", err)
+	os.Exit(1)
 }
 
 func getConfigOptions() config.Options {
@@ -3,7 +3,8 @@ package database
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
+	"os"
 
 	pgxuuid "github.com/jackc/pgx-gofrs-uuid"
 	"github.com/jackc/pgx/v5"
@@ -18,7 +19,8 @@ func NewDB(cfg config.WeCreditConfig) *pgxpool.Pool {
 	// Create a database connection pool
 	dbConfig, err := pgxpool.ParseConfig(dsn)
 	if err != nil {
-		log.Fatalf("Unable to parse DATABASE_URL: %v\n", err)
+		slog.Error("Unable to parse DATABASE_URL", "err", err)
+		os.Exit(1)
 	}
 
 	// Register the uuid type
@@ -29,7 +31,8 @@ func NewDB(cfg config.WeCreditConfig) *pgxpool.Pool {
 	// Create the connection pool
 	dbPool, err := pgxpool.NewWithConfig(context.Background(), dbConfig)
 	if err != nil {
-		log.Fatalf("Unable to create connection pool: %v\n", err)
+		slog.Error("Unable to create connection pool", "err", err)
+		os.Exit(1)
 	}
 	return dbPool
 }
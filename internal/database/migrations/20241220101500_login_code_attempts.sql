@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."login_codes"
+    ADD COLUMN "attempts" int NOT NULL DEFAULT 0,
+    ADD COLUMN "locked_until" timestamptz;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."login_codes"
+    DROP COLUMN IF EXISTS "locked_until",
+    DROP COLUMN IF EXISTS "attempts";
+
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Codes were stored in plaintext until now and cannot be hashed without the server secret.
+-- They live for a few minutes only, so pending codes are dropped and users simply request a new one.
+-- Locked rows are kept so the lockout still applies, but their plaintext code is wiped.
+DELETE FROM "public"."login_codes" WHERE "status" = 'PENDING';
+
+UPDATE "public"."login_codes" SET "code" = '' WHERE "code" <> '';
+
+COMMENT ON COLUMN "public"."login_codes"."code" IS 'HMAC-SHA256 of the otp, hex encoded';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+COMMENT ON COLUMN "public"."login_codes"."code" IS NULL;
+
+-- +goose StatementEnd
@@ -0,0 +1,31 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Table Definition
+CREATE TABLE "public"."sessions" (
+    "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" uuid NOT NULL REFERENCES "public"."users" ("id") ON DELETE CASCADE,
+    "family_id" uuid NOT NULL,
+    "refresh_token_hash" varchar NOT NULL,
+    "device" varchar,
+    "ip_address" varchar,
+    "user_agent" varchar,
+    "expires_at" timestamptz NOT NULL,
+    "rotated_at" timestamptz,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "sessions_refresh_token_hash_idx" ON "public"."sessions" ("refresh_token_hash");
+
+CREATE INDEX "sessions_family_id_idx" ON "public"."sessions" ("family_id");
+
+CREATE INDEX "sessions_user_id_idx" ON "public"."sessions" ("user_id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."sessions";
+
+-- +goose StatementEnd
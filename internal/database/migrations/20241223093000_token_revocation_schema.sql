@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."users" ADD COLUMN "tokens_valid_after" timestamptz;
+
+-- Table Definition
+CREATE TABLE "public"."revoked_tokens" (
+    "jti" varchar NOT NULL,
+    "user_id" uuid NOT NULL REFERENCES "public"."users" ("id") ON DELETE CASCADE,
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("jti")
+);
+
+CREATE INDEX "revoked_tokens_expires_at_idx" ON "public"."revoked_tokens" ("expires_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."revoked_tokens";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "tokens_valid_after";
+
+-- +goose StatementEnd
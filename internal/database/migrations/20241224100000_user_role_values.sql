@@ -0,0 +1,19 @@
+-- +goose NO TRANSACTION
+-- +goose Up
+ALTER TYPE "public"."user_role" ADD VALUE IF NOT EXISTS 'ADMIN';
+
+ALTER TYPE "public"."user_role" ADD VALUE IF NOT EXISTS 'LOAN_OFFICER';
+
+ALTER TYPE "public"."user_role" ADD VALUE IF NOT EXISTS 'SUPPORT';
+
+-- +goose Down
+-- Postgres cannot drop enum values, so the type is recreated with USER only
+UPDATE "public"."users" SET "role" = 'USER' WHERE "role" <> 'USER';
+
+ALTER TABLE "public"."users" ALTER COLUMN "role" TYPE varchar;
+
+DROP TYPE IF EXISTS "public"."user_role";
+
+CREATE TYPE "public"."user_role" AS ENUM ('USER');
+
+ALTER TABLE "public"."users" ALTER COLUMN "role" TYPE "public"."user_role" USING "role"::"public"."user_role";
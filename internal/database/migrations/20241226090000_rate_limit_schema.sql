@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Table Definition
+CREATE TABLE "public"."rate_limit_hits" (
+    "key" varchar NOT NULL,
+    "hit_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX "rate_limit_hits_key_hit_at_idx" ON "public"."rate_limit_hits" ("key", "hit_at");
+
+CREATE INDEX "rate_limit_hits_hit_at_idx" ON "public"."rate_limit_hits" ("hit_at");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."rate_limit_hits";
+
+-- +goose StatementEnd
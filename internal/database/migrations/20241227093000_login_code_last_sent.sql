@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."login_codes" ADD COLUMN "last_sent_at" timestamptz;
+
+UPDATE "public"."login_codes" SET "last_sent_at" = "updated_at";
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."login_codes" DROP COLUMN IF EXISTS "last_sent_at";
+
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."users" ADD COLUMN "email" varchar;
+
+ALTER TABLE "public"."users" ADD COLUMN "email_verified_at" timestamptz;
+
+CREATE UNIQUE INDEX "users_email_idx" ON "public"."users" (LOWER("email"));
+
+ALTER TABLE "public"."login_codes" ADD COLUMN "purpose" varchar NOT NULL DEFAULT 'LOGIN';
+
+ALTER TABLE "public"."login_codes" ADD COLUMN "target" varchar;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."login_codes" DROP COLUMN IF EXISTS "target";
+
+ALTER TABLE "public"."login_codes" DROP COLUMN IF EXISTS "purpose";
+
+DROP INDEX IF EXISTS "public"."users_email_idx";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "email_verified_at";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "email";
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."login_codes" ADD COLUMN "message_id" varchar;
+
+CREATE INDEX "login_codes_message_id_idx" ON "public"."login_codes" ("message_id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS "public"."login_codes_message_id_idx";
+
+ALTER TABLE "public"."login_codes" DROP COLUMN IF EXISTS "message_id";
+
+-- +goose StatementEnd
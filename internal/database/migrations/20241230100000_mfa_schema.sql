@@ -0,0 +1,37 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."users" ADD COLUMN "totp_secret" varchar;
+
+ALTER TABLE "public"."users" ADD COLUMN "totp_enabled_at" timestamptz;
+
+ALTER TABLE "public"."users" ADD COLUMN "totp_last_step" bigint;
+
+ALTER TABLE "public"."sessions" ADD COLUMN "mfa" boolean NOT NULL DEFAULT false;
+
+-- Table Definition
+CREATE TABLE "public"."recovery_codes" (
+    "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" uuid NOT NULL REFERENCES "public"."users" ("id") ON DELETE CASCADE,
+    "code_hash" varchar NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "recovery_codes_user_id_code_hash_idx" ON "public"."recovery_codes" ("user_id", "code_hash");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."recovery_codes";
+
+ALTER TABLE "public"."sessions" DROP COLUMN IF EXISTS "mfa";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "totp_last_step";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "totp_enabled_at";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "totp_secret";
+
+-- +goose StatementEnd
@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Table Definition
+CREATE TABLE "public"."user_devices" (
+    "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" uuid NOT NULL REFERENCES "public"."users" ("id") ON DELETE CASCADE,
+    "device_id" varchar NOT NULL DEFAULT '',
+    "fingerprint" varchar NOT NULL,
+    "name" varchar,
+    "user_agent" varchar,
+    "last_ip_address" varchar,
+    "last_seen_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "revoked_at" timestamptz,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "user_devices_user_id_device_id_fingerprint_idx" ON "public"."user_devices" ("user_id", "device_id", "fingerprint");
+
+ALTER TABLE "public"."sessions" ADD COLUMN "user_device_id" uuid REFERENCES "public"."user_devices" ("id") ON DELETE SET NULL;
+
+CREATE INDEX "sessions_user_device_id_idx" ON "public"."sessions" ("user_device_id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."sessions" DROP COLUMN IF EXISTS "user_device_id";
+
+DROP TABLE IF EXISTS "public"."user_devices";
+
+-- +goose StatementEnd
@@ -0,0 +1,28 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Table Definition
+CREATE TABLE "public"."login_events" (
+    "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" uuid REFERENCES "public"."users" ("id") ON DELETE SET NULL,
+    "username" varchar NOT NULL,
+    "outcome" varchar NOT NULL,
+    "channel" varchar,
+    "ip_address" varchar,
+    "user_agent" varchar,
+    "request_id" varchar,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "login_events_user_id_created_at_idx" ON "public"."login_events" ("user_id", "created_at" DESC);
+
+CREATE INDEX "login_events_username_created_at_idx" ON "public"."login_events" ("username", "created_at" DESC);
+
+CREATE INDEX "login_events_created_at_idx" ON "public"."login_events" ("created_at" DESC);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."login_events";
+
+-- +goose StatementEnd
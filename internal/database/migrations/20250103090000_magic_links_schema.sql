@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Table Definition
+CREATE TABLE "public"."magic_links" (
+    "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" uuid NOT NULL REFERENCES "public"."users" ("id") ON DELETE CASCADE,
+    "token_hash" varchar NOT NULL,
+    "binding_hash" varchar NOT NULL,
+    "fingerprint" varchar NOT NULL,
+    "channel" varchar NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamptz DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE UNIQUE INDEX "magic_links_token_hash_idx" ON "public"."magic_links" ("token_hash");
+
+CREATE INDEX "magic_links_user_id_idx" ON "public"."magic_links" ("user_id");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."magic_links";
+
+-- +goose StatementEnd
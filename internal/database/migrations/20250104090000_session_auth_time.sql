@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE "public"."sessions" ADD COLUMN "auth_time" timestamptz;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."sessions" DROP COLUMN IF EXISTS "auth_time";
+
+-- +goose StatementEnd
@@ -0,0 +1,35 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE "public"."user_status" AS ENUM ('ACTIVE', 'SUSPENDED', 'DEACTIVATED', 'DELETED');
+
+ALTER TABLE "public"."users" ADD COLUMN "status" "public"."user_status" NOT NULL DEFAULT 'ACTIVE';
+
+ALTER TABLE "public"."users" ADD COLUMN "status_reason" varchar;
+
+ALTER TABLE "public"."users" ADD COLUMN "status_changed_at" timestamptz;
+
+ALTER TABLE "public"."users" ADD COLUMN "deleted_at" timestamptz;
+
+-- A user is soft-deleted exactly when its status is DELETED
+ALTER TABLE "public"."users" ADD CONSTRAINT "users_deleted_at_check" CHECK (("status" = 'DELETED') = ("deleted_at" IS NOT NULL));
+
+CREATE INDEX "users_status_idx" ON "public"."users" ("status");
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS "public"."users_status_idx";
+
+ALTER TABLE "public"."users" DROP CONSTRAINT IF EXISTS "users_deleted_at_check";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "deleted_at";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "status_changed_at";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "status_reason";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "status";
+
+DROP TYPE IF EXISTS "public"."user_status";
+
+-- +goose StatementEnd
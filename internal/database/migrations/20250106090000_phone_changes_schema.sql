@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Table Definition
+CREATE TABLE "public"."phone_changes" (
+    "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" uuid NOT NULL REFERENCES "public"."users" ("id") ON DELETE CASCADE,
+    "old_user_name" varchar NOT NULL,
+    "new_user_name" varchar NOT NULL,
+    "method" varchar NOT NULL,
+    "actor_id" uuid REFERENCES "public"."users" ("id") ON DELETE SET NULL,
+    "reason" varchar,
+    "ip_address" varchar,
+    "user_agent" varchar,
+    "request_id" varchar,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX "phone_changes_user_id_created_at_idx" ON "public"."phone_changes" ("user_id", "created_at" DESC);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."phone_changes";
+
+-- +goose StatementEnd
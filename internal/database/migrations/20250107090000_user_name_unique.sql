@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- A mobile number belongs to a single live account, soft-deleted accounts release it.
+-- Fails if duplicates already exist, they have to be merged or deleted by hand before migrating.
+CREATE UNIQUE INDEX "users_user_name_idx" ON "public"."users" ("user_name") WHERE "deleted_at" IS NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS "public"."users_user_name_idx";
+
+-- +goose StatementEnd
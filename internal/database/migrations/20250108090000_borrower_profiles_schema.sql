@@ -0,0 +1,32 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TYPE "public"."gender" AS ENUM ('MALE', 'FEMALE', 'OTHER');
+
+CREATE TYPE "public"."occupation" AS ENUM ('SALARIED', 'SELF_EMPLOYED', 'BUSINESS', 'STUDENT', 'RETIRED', 'HOMEMAKER', 'UNEMPLOYED');
+
+-- Table Definition
+CREATE TABLE "public"."borrower_profiles" (
+    "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+    "user_id" uuid NOT NULL REFERENCES "public"."users" ("id") ON DELETE CASCADE,
+    "date_of_birth" date NOT NULL,
+    "gender" "public"."gender" NOT NULL,
+    "occupation" "public"."occupation" NOT NULL,
+    "employer" varchar,
+    "current_address" jsonb NOT NULL,
+    "permanent_address" jsonb NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "borrower_profiles_user_id_key" UNIQUE ("user_id")
+);
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS "public"."borrower_profiles";
+
+DROP TYPE IF EXISTS "public"."occupation";
+
+DROP TYPE IF EXISTS "public"."gender";
+
+-- +goose StatementEnd
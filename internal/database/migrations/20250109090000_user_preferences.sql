@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Missing keys take their default value, so existing users start with every default
+ALTER TABLE "public"."users" ADD COLUMN "preferences" jsonb NOT NULL DEFAULT '{}';
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "preferences";
+
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Like the mobile number, an email belongs to a single live account and soft-deleted accounts release it.
+DROP INDEX IF EXISTS "public"."users_email_idx";
+CREATE UNIQUE INDEX "users_email_idx" ON "public"."users" (LOWER("email")) WHERE "deleted_at" IS NULL;
+
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+-- Fails if a deleted and a live account share an email, one of them has to be changed by hand before rolling back.
+DROP INDEX IF EXISTS "public"."users_email_idx";
+CREATE UNIQUE INDEX "users_email_idx" ON "public"."users" (LOWER("email"));
+
+-- +goose StatementEnd
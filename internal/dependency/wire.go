@@ -10,6 +10,9 @@ import (
 	"github.com/weCredit/internal/http/api"
 	"github.com/weCredit/internal/http/controller"
 	"github.com/weCredit/internal/pkg/config"
+	"github.com/weCredit/internal/pkg/metrics"
+	"github.com/weCredit/internal/pkg/notification"
+	"github.com/weCredit/internal/pkg/ratelimit"
 	"github.com/weCredit/internal/pkg/security"
 	"github.com/weCredit/internal/pkg/util"
 	"github.com/weCredit/internal/repository"
@@ -35,12 +38,35 @@ func NewWeCredit(cfg config.WeCreditConfig, db *pgxpool.Pool) (*api.WeCreditApi,
 		util.NewAppUtil,
 		repository.NewTransactioner,
 		security.NewJwtSecurityManager,
+		security.NewMagicLinkSigner,
+		security.NewOtpHasher,
+		security.NewRevocationStore,
+		security.NewTotpManager,
+		metrics.NewDeliveryRecorder,
+		notification.NewSender,
+		ratelimit.NewLimiter,
+		repository.NewBorrowerProfileRepository,
 		repository.NewLoginCodeRepository,
+		repository.NewLoginEventRepository,
+		repository.NewMagicLinkRepository,
+		repository.NewPhoneChangeRepository,
+		repository.NewRateLimitRepository,
+		repository.NewRecoveryCodeRepository,
+		repository.NewRevokedTokenRepository,
+		repository.NewSessionRepository,
+		repository.NewUserDeviceRepository,
 		repository.NewUserRepository,
 
+		service.NewBorrowerProfileService,
+		service.NewMfaService,
+		service.NewNotificationService,
 		service.NewUserService,
 
+		controller.NewBorrowerProfileController,
+		controller.NewMfaController,
+		controller.NewNotificationController,
 		controller.NewUserController,
+		controller.NewWellKnownController,
 
 		api.NewWeCreditApi,
 	)
@@ -12,6 +12,9 @@ import (
 	"github.com/weCredit/internal/http/api"
 	"github.com/weCredit/internal/http/controller"
 	"github.com/weCredit/internal/pkg/config"
+	"github.com/weCredit/internal/pkg/metrics"
+	"github.com/weCredit/internal/pkg/notification"
+	"github.com/weCredit/internal/pkg/ratelimit"
 	"github.com/weCredit/internal/pkg/security"
 	"github.com/weCredit/internal/pkg/util"
 	"github.com/weCredit/internal/repository"
@@ -34,13 +37,51 @@ func NewDatabaseConfig(cfg config.WeCreditConfig) (*pgxpool.Pool, error) {
 }
 
 func NewWeCredit(cfg config.WeCreditConfig, db *pgxpool.Pool) (*api.WeCreditApi, error) {
-	appUtil := util.NewAppUtil()
+	borrowerProfileRepository := repository.NewBorrowerProfileRepository(db)
+	borrowerProfileService := service.NewBorrowerProfileService(borrowerProfileRepository)
+	borrowerProfileController := controller.NewBorrowerProfileController(borrowerProfileService)
+	deliveryRecorder := metrics.NewDeliveryRecorder()
 	loginCodeRepository := repository.NewLoginCodeRepository(db)
-	manager := security.NewJwtSecurityManager(cfg)
-	transactioner := repository.NewTransactioner(db)
+	notificationService := service.NewNotificationService(deliveryRecorder, loginCodeRepository)
+	notificationController := controller.NewNotificationController(notificationService)
+	appUtil := util.NewAppUtil()
+	sender, err := notification.NewSender(cfg)
+	if err != nil {
+		return nil, err
+	}
+	otpHasher, err := security.NewOtpHasher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rateLimitRepository := repository.NewRateLimitRepository(db)
+	limiter, err := ratelimit.NewLimiter(cfg, rateLimitRepository)
+	if err != nil {
+		return nil, err
+	}
+	revokedTokenRepository := repository.NewRevokedTokenRepository(db)
 	userRepository := repository.NewUserRepository(db)
-	userService := service.NewUserService(appUtil, cfg, loginCodeRepository, manager, transactioner, userRepository)
+	revocationStore := security.NewRevocationStore(cfg, revokedTokenRepository, userRepository)
+	manager, err := security.NewJwtSecurityManager(cfg)
+	if err != nil {
+		return nil, err
+	}
+	recoveryCodeRepository := repository.NewRecoveryCodeRepository(db)
+	totpManager, err := security.NewTotpManager(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transactioner := repository.NewTransactioner(db)
+	mfaService := service.NewMfaService(appUtil, cfg, otpHasher, recoveryCodeRepository, limiter, totpManager, transactioner, userRepository)
+	mfaController := controller.NewMfaController(mfaService)
+	sessionRepository := repository.NewSessionRepository(db)
+	userDeviceRepository := repository.NewUserDeviceRepository(db)
+	loginEventRepository := repository.NewLoginEventRepository(db)
+	magicLinkRepository := repository.NewMagicLinkRepository(db)
+	phoneChangeRepository := repository.NewPhoneChangeRepository(db)
+	magicLinkSigner := security.NewMagicLinkSigner(cfg)
+	userService := service.NewUserService(appUtil, cfg, loginCodeRepository, loginEventRepository, magicLinkRepository, magicLinkSigner, mfaService, sender, otpHasher, phoneChangeRepository, limiter, revocationStore, manager, sessionRepository, transactioner, userDeviceRepository, userRepository)
 	userController := controller.NewUserController(userService)
-	weCreditApi := api.NewWeCreditApi(cfg, userController)
+	wellKnownController := controller.NewWellKnownController(manager)
+	weCreditApi := api.NewWeCreditApi(cfg, revocationStore, manager, userService, borrowerProfileController, mfaController, notificationController, userController, wellKnownController)
 	return weCreditApi, nil
 }
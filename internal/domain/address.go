@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"regexp"
+	"strings"
+)
+
+// pincodePattern matches an Indian postal index number, six digits that do not start with 0
+var pincodePattern = regexp.MustCompile(`^[1-9][0-9]{5}$`)
+
+// indianStates maps the states and union territories of India to the first digits of their pincodes,
+// the first digit of a pincode being its postal zone. Yanam, in Puducherry, lies in the Andhra Pradesh zone.
+var indianStates = map[string]string{
+	"Andaman and Nicobar Islands": "7",
+	"Andhra Pradesh":              "5",
+	"Arunachal Pradesh":           "7",
+	"Assam":                       "7",
+	"Bihar":                       "8",
+	"Chandigarh":                  "1",
+	"Chhattisgarh":                "4",
+	"Dadra and Nagar Haveli and Daman and Diu": "3",
+	"Delhi":             "1",
+	"Goa":               "4",
+	"Gujarat":           "3",
+	"Haryana":           "1",
+	"Himachal Pradesh":  "1",
+	"Jammu and Kashmir": "1",
+	"Jharkhand":         "8",
+	"Karnataka":         "5",
+	"Kerala":            "6",
+	"Ladakh":            "1",
+	"Lakshadweep":       "6",
+	"Madhya Pradesh":    "4",
+	"Maharashtra":       "4",
+	"Manipur":           "7",
+	"Meghalaya":         "7",
+	"Mizoram":           "7",
+	"Nagaland":          "7",
+	"Odisha":            "7",
+	"Puducherry":        "56",
+	"Punjab":            "1",
+	"Rajasthan":         "3",
+	"Sikkim":            "7",
+	"Tamil Nadu":        "6",
+	"Telangana":         "5",
+	"Tripura":           "7",
+	"Uttar Pradesh":     "2",
+	"Uttarakhand":       "2",
+	"West Bengal":       "7",
+}
+
+// armyPostalZone is the first digit of the pincodes of the Army Postal Service, which are not tied to a state
+const armyPostalZone = '9'
+
+// IsValidPincode reports whether the value is a well formed Indian pincode
+func IsValidPincode(pincode string) bool {
+	return pincodePattern.MatchString(pincode)
+}
+
+// IndianState returns the canonical name of a state or union territory of India, matched regardless of case and spacing
+func IndianState(name string) (state string, ok bool) {
+	name = strings.Join(strings.Fields(name), " ")
+	for state = range indianStates {
+		if strings.EqualFold(state, name) {
+			return state, true
+		}
+	}
+	return "", false
+}
+
+// MatchesPincode reports whether the pincode of the address lies in the postal zone of its state
+func (a Address) MatchesPincode() bool {
+	state, ok := IndianState(a.State)
+	if !ok || !IsValidPincode(a.Pincode) {
+		return false
+	}
+	return a.Pincode[0] == armyPostalZone || strings.IndexByte(indianStates[state], a.Pincode[0]) >= 0
+}
@@ -4,11 +4,15 @@ import (
 	"context"
 	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
 )
 
+// DateLayout is the layout of the dates without a time of day exchanged with clients
+const DateLayout = "2006-01-02"
+
 type (
 	// JSONB represents a JSONB type
 	JSONB map[string]interface{} // @name JSONB
@@ -17,18 +21,24 @@ type (
 type (
 	// Address Defines the model for address
 	Address struct {
-		Location string `json:"location" example:"Ahmedabad"`
-		Street   string `json:"street" example:"Near Railway Station"`
-		City     string `json:"city" example:"Ahmedabad"`
-		State    string `json:"state" example:"Gujarat"`
-		Country  string `json:"country" example:"India"`
-		Pincode  string `json:"pincode" example:"380009"`
+		Location string `json:"location" validate:"max=200" example:"Ahmedabad"`
+		Street   string `json:"street" validate:"required,max=200" example:"Near Railway Station"`
+		City     string `json:"city" validate:"required,max=100" example:"Ahmedabad"`
+		State    string `json:"state" validate:"required,indian_state" example:"Gujarat"`
+		Country  string `json:"country" validate:"omitempty,oneof=India" example:"India"`
+		Pincode  string `json:"pincode" validate:"required,pincode" example:"380009"`
 	} // @name Address
 
 	// Base define the base model
 	Base struct {
 		ID uuid.UUID `json:"id" db:"id" example:""`
 	} // @name Base
+	// ClientInfo define the details of the client a request came from
+	ClientInfo struct {
+		IPAddress string `json:"-"`
+		UserAgent string `json:"-"`
+		RequestID string `json:"-"`
+	} // @name ClientInfo
 	// BaseAudit define the base audit model
 	BaseAudit struct {
 		CreatedAt time.Time `json:"created_at" db:"created_at"`
@@ -59,16 +69,52 @@ type (
 	}
 )
 
-// Value implements the driver.Valuer interface,
-func (j *JSONB) Value() (driver.Value, error) {
+// Value implements the driver.Valuer interface, a nil map is stored as an empty object
+func (j JSONB) Value() (driver.Value, error) {
+	if j == nil {
+		return "{}", nil
+	}
 	valueString, err := json.Marshal(j)
 	return string(valueString), err
 }
 
-// Scan implements the sql.Scanner interface,
+// Scan implements the sql.Scanner interface. pgx hands jsonb columns over as raw bytes, or as the decoded map
+// when the column was already decoded.
 func (j *JSONB) Scan(value interface{}) error {
-	if err := json.Unmarshal([]byte(value.(string)), &j); err != nil {
-		return err
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, j)
+	case string:
+		return json.Unmarshal([]byte(v), j)
+	case map[string]interface{}:
+		*j = v
+		return nil
+	default:
+		return fmt.Errorf("cannot scan %T into JSONB", value)
+	}
+}
+
+// MergePatch applies a JSON merge patch (RFC 7386) and returns the result, leaving the receiver untouched.
+// A null in the patch removes the key, objects are merged recursively and any other value replaces the current one.
+func (j JSONB) MergePatch(patch JSONB) JSONB {
+	result := make(JSONB, len(j))
+	for k, v := range j {
+		result[k] = v
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(result, k)
+			continue
+		}
+		if obj, ok := v.(map[string]interface{}); ok {
+			target, _ := result[k].(map[string]interface{})
+			result[k] = map[string]interface{}(JSONB(target).MergePatch(obj))
+			continue
+		}
+		result[k] = v
 	}
-	return nil
+	return result
 }
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// Gender defines the gender of a borrower
+	Gender string // @name Gender
+	// Occupation defines how a borrower earns a living
+	Occupation string // @name Occupation
+)
+
+const (
+	GenderMALE   Gender = "MALE"
+	GenderFEMALE Gender = "FEMALE"
+	GenderOTHER  Gender = "OTHER"
+)
+
+const (
+	OccupationSALARIED      Occupation = "SALARIED"
+	OccupationSELF_EMPLOYED Occupation = "SELF_EMPLOYED"
+	OccupationBUSINESS      Occupation = "BUSINESS"
+	OccupationSTUDENT       Occupation = "STUDENT"
+	OccupationRETIRED       Occupation = "RETIRED"
+	OccupationHOMEMAKER     Occupation = "HOMEMAKER"
+	OccupationUNEMPLOYED    Occupation = "UNEMPLOYED"
+)
+
+type (
+	// BorrowerProfile defines model for BorrowerProfile, the demographic data a loan is underwritten on.
+	// A user has at most one profile.
+	BorrowerProfile struct {
+		Base
+		UserID           uuid.UUID  `db:"user_id" json:"user_id"`
+		DateOfBirth      time.Time  `db:"date_of_birth" json:"date_of_birth" example:"1990-05-17T00:00:00Z"`
+		Gender           Gender     `db:"gender" json:"gender" example:"FEMALE"`
+		Occupation       Occupation `db:"occupation" json:"occupation" example:"SALARIED"`
+		Employer         *string    `db:"employer" json:"employer,omitempty" example:"Acme Textiles Pvt Ltd"`
+		CurrentAddress   Address    `db:"current_address" json:"current_address"`
+		PermanentAddress Address    `db:"permanent_address" json:"permanent_address"`
+		BaseAudit
+	} // @name BorrowerProfile
+)
+
+type (
+	// SaveBorrowerProfileInput define the module for the SaveBorrowerProfileInput. Without a permanent address,
+	// the current address is used for both.
+	SaveBorrowerProfileInput struct {
+		UserID           uuid.UUID  `json:"-"`
+		DateOfBirth      string     `json:"date_of_birth" validate:"required,date" example:"1990-05-17"`
+		Gender           Gender     `json:"gender" validate:"required,oneof=MALE FEMALE OTHER" example:"FEMALE"`
+		Occupation       Occupation `json:"occupation" validate:"required,oneof=SALARIED SELF_EMPLOYED BUSINESS STUDENT RETIRED HOMEMAKER UNEMPLOYED" example:"SALARIED"`
+		Employer         string     `json:"employer" validate:"max=100" example:"Acme Textiles Pvt Ltd"`
+		CurrentAddress   Address    `json:"current_address"`
+		PermanentAddress *Address   `json:"permanent_address"`
+	} // @name SaveBorrowerProfileInput
+)
+
+type (
+	// BorrowerProfileRepository defines the methods that any borrower-profile repository should implement.
+	BorrowerProfileRepository interface {
+		// FindByUserID finds the profile of the user
+		FindByUserID(ctx context.Context, userID uuid.UUID) (result BorrowerProfile, err error)
+		// Upsert creates the profile of the user or replaces the existing one
+		Upsert(ctx context.Context, entity *BorrowerProfile) (err error)
+	}
+
+	// BorrowerProfileService defines the methods that any borrower-profile service should implement
+	BorrowerProfileService interface {
+		// FindByUserID finds the profile of the user
+		FindByUserID(userID uuid.UUID) (result BorrowerProfile, err error)
+		// Save validates and stores the profile of the user
+		Save(input SaveBorrowerProfileInput) (result BorrowerProfile, err error)
+	}
+)
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// UserDevice defines model for UserDevice.
+	//
+	// A device is identified by the device ID the client supplies together with the fingerprint of its user agent,
+	// so a copied device ID on another handset is still a new device.
+	UserDevice struct {
+		Base
+		UserID        uuid.UUID  `db:"user_id" json:"-"`
+		DeviceID      string     `db:"device_id" json:"device_id,omitempty" example:"3f1c2a9e-7b44-4b8e-9d0a-5c6f1e2d3b4a"`
+		Fingerprint   string     `db:"fingerprint" json:"-"`
+		Name          *string    `db:"name" json:"name,omitempty" example:"Pixel 8"`
+		UserAgent     *string    `db:"user_agent" json:"user_agent,omitempty"`
+		LastIPAddress *string    `db:"last_ip_address" json:"last_ip_address,omitempty" example:"203.0.113.10"`
+		LastSeenAt    time.Time  `db:"last_seen_at" json:"last_seen_at"`
+		RevokedAt     *time.Time `db:"revoked_at" json:"revoked_at,omitempty"`
+		BaseAudit
+	} // @name UserDevice
+)
+
+type (
+	// RevokeDeviceInput define the module for the RevokeDeviceInput
+	RevokeDeviceInput struct {
+		ID       uuid.UUID `json:"-"`
+		DeviceID uuid.UUID `json:"-"`
+	} // @name RevokeDeviceInput
+)
+
+type (
+	// UserDeviceRepository defines the methods that any user-device repository should implement.
+	UserDeviceRepository interface {
+		// FindByFingerprint returns the device of the user with the given device id and fingerprint
+		FindByFingerprint(ctx context.Context, userID uuid.UUID, deviceID, fingerprint string) (result UserDevice, err error)
+		// FindAllByUserID returns every device of the user, most recently seen first
+		FindAllByUserID(ctx context.Context, userID uuid.UUID) (result []UserDevice, err error)
+		// Create creates a new record
+		Create(ctx context.Context, entity *UserDevice) (err error)
+		// Update updates the name, user agent, last ip address, last seen time and revocation of the device
+		Update(ctx context.Context, id uuid.UUID, entity *UserDevice) (err error)
+		// Revoke revokes an active device of the user, it returns DataNotFoundError when there is none
+		Revoke(ctx context.Context, userID, id uuid.UUID) (err error)
+	}
+)
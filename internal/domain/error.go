@@ -79,12 +79,61 @@ func (e SystemError) Error() string {
 	return e.Message
 }
 
+// TooManyRequestsError defines model for too many requests error.
+type TooManyRequestsError struct {
+	Code       string `json:"code" example:"OTP_LOCKED"`
+	Message    string `json:"message" example:"Too many failed attempts. Please try again later"`
+	RetryAfter int64  `json:"retry_after" example:"900"`
+} // @name TooManyRequestsError
+
+func (e TooManyRequestsError) Error() string {
+	return e.Message
+}
+
+// ConflictError defines model for conflict error.
+type ConflictError struct {
+	Code    string `json:"code" example:"USERNAME_EXISTS"`
+	Message string `json:"message" example:"User with this mobile number already exists"`
+} // @name ConflictError
+
+func (e ConflictError) Error() string {
+	return e.Message
+}
+
 const (
 	ErrorCodeINVALID_REQUEST       = "INVALID_REQUEST"
 	ErrorCodeVALIDATION_ERROR      = "VALIDATION_ERROR"
 	ErrorCodeINTERNAL_SERVER_ERROR = "INTERNAL_SERVER_ERROR"
 	ErrorCodeUNAUTHORIZED          = "UNAUTHORIZED"
 	ErrorCodeFORBIDDEN_ACCESS      = "FORBIDDEN_ACCESS"
+	ErrorCodeINVALID_OTP           = "INVALID_OTP"
+	ErrorCodeOTP_EXPIRED           = "OTP_EXPIRED"
+	ErrorCodeOTP_LOCKED            = "OTP_LOCKED"
+	ErrorCodeRATE_LIMITED          = "RATE_LIMITED"
+	ErrorCodeOTP_RESEND_COOLDOWN   = "OTP_RESEND_COOLDOWN"
+	ErrorCodeOTP_RESEND_LIMIT      = "OTP_RESEND_LIMIT"
+	ErrorCodeEMAIL_NOT_VERIFIED    = "EMAIL_NOT_VERIFIED"
+	ErrorCodeEMAIL_EXISTS          = "EMAIL_EXISTS"
+	ErrorCodeCHANNEL_UNAVAILABLE   = "CHANNEL_UNAVAILABLE"
+	ErrorCodeMFA_REQUIRED          = "MFA_REQUIRED"
+	ErrorCodeINVALID_MFA_CODE      = "INVALID_MFA_CODE"
+	ErrorCodeTOTP_ALREADY_ENABLED  = "TOTP_ALREADY_ENABLED"
+	ErrorCodeTOTP_NOT_ENABLED      = "TOTP_NOT_ENABLED"
+	ErrorCodeINVALID_MAGIC_LINK    = "INVALID_MAGIC_LINK"
+	ErrorCodeMAGIC_LINK_EXPIRED    = "MAGIC_LINK_EXPIRED"
+	ErrorCodeLOGIN_MODE_DISABLED   = "LOGIN_MODE_DISABLED"
+	ErrorCodeSTEP_UP_REQUIRED      = "STEP_UP_REQUIRED"
+	ErrorCodeACCOUNT_SUSPENDED     = "ACCOUNT_SUSPENDED"
+	ErrorCodeACCOUNT_DEACTIVATED   = "ACCOUNT_DEACTIVATED"
+	ErrorCodeINVALID_STATUS_CHANGE = "INVALID_STATUS_CHANGE"
+	ErrorCodeUSERNAME_EXISTS       = "USERNAME_EXISTS"
+	ErrorCodeALREADY_EXISTS        = "ALREADY_EXISTS"
+	ErrorCodeINVALID_REFERENCE     = "INVALID_REFERENCE"
+	ErrorCodeCONSTRAINT_VIOLATION  = "CONSTRAINT_VIOLATION"
+	ErrorCodeCONCURRENT_UPDATE     = "CONCURRENT_UPDATE"
+	ErrorCodeINVALID_BIRTH_DATE    = "INVALID_BIRTH_DATE"
+	ErrorCodeEMPLOYER_REQUIRED     = "EMPLOYER_REQUIRED"
+	ErrorCodeINVALID_ADDRESS       = "INVALID_ADDRESS"
 )
 
 const (
@@ -92,6 +141,40 @@ const (
 	MessageUSERNAMEEREXISTS          = "User with this mobile number already exists"
 	MessagePATIENTNAMEEXISTS         = "User with this name is already registered in the system"
 	MessageNOT_ALLOWED_FOR_OPERATION = "You are not allowed to perform this operation please contact with admin"
+	MessageINVALIDOTP                = "The OTP you entered is invalid"
+	MessageOTPEXPIRED                = "The OTP has expired, please request a new one"
+	MessageOTPLOCKED                 = "Too many failed attempts. Please try again later"
+	MessageRATELIMITED               = "Too many requests. Please try again later"
+	MessageOTPRESENDCOOLDOWN         = "Please wait before requesting a new OTP"
+	MessageOTPRESENDLIMIT            = "You have requested too many OTPs. Please try again later"
+	MessageEMAILNOTVERIFIED          = "No verified email is linked to this account"
+	MessageEMAILEXISTS               = "This email is already linked to another account"
+	MessageCHANNELUNAVAILABLE        = "This delivery channel is not available right now"
+	MessageMFAREQUIRED               = "A code from your authenticator app or a recovery code is required"
+	MessageINVALIDMFACODE            = "The authenticator or recovery code you entered is invalid"
+	MessageTOTPALREADYENABLED        = "Authenticator app verification is already enabled"
+	MessageTOTPNOTENABLED            = "Authenticator app verification is not enabled"
+	MessageINVALIDMAGICLINK          = "This login link is invalid, was already used or was requested from another device"
+	MessageMAGICLINKEXPIRED          = "This login link has expired, please request a new one"
+	MessageLOGINMODEDISABLED         = "This login mode is not enabled"
+	MessageSTEPUPREQUIRED            = "Please confirm your identity again with a new OTP"
+	MessageACCOUNTSUSPENDED          = "This account is suspended, please contact support"
+	MessageACCOUNTDEACTIVATED        = "This account is deactivated, please contact support to reactivate it"
+	MessageINVALIDSTATUSCHANGE       = "The account cannot be moved from its current status to this one"
+	MessageSAMEPHONENUMBER           = "The new mobile number is the same as the current one"
+	MessageFULLNAMEREQUIRED          = "The full name cannot be empty"
+	MessagePROFILEFIELDREADONLY      = "The mobile number, email and role cannot be changed through the profile"
+	MessageALREADYEXISTS             = "This record already exists"
+	MessageINVALIDREFERENCE          = "The request refers to a record that does not exist"
+	MessageCONSTRAINTVIOLATION       = "The request breaks a rule on the stored data"
+	MessageCONCURRENTUPDATE          = "The record was changed by another request, please try again"
+	MessageSOMETHINGWENTWRONG        = "Oops! Something went wrong. Please try again later"
+	MessageINVALIDBIRTHDATE          = "Borrowers must be between 18 and 100 years old"
+	MessageEMPLOYERREQUIRED          = "The employer is required for salaried borrowers"
+	MessageINVALIDADDRESS            = "The pincode of the address does not belong to its state"
+	MessageJSONOBJECTREQUIRED        = "The request body must be a JSON object"
+	MessageINVALIDPAGINATION         = "The page and size must be numbers"
+	MessagePAGETOOLARGE              = "The page cannot exceed 10000, narrow the filters instead"
 
 	MessageUNAUTHORIZEDACCESS = "You are not authorized to access this resource"
 	MessageFORBIDDENACCESS    = "You are forbidden from accessing this resource"
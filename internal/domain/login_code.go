@@ -7,26 +7,75 @@ import (
 	"github.com/gofrs/uuid/v5"
 )
 
-// LoginCodeStatus defines model for LoginCode.Status.
-type LoginCodeStatus string
+type (
+	// LoginCodeStatus defines model for LoginCode.Status.
+	LoginCodeStatus string
+	// LoginCodePurpose defines model for LoginCode.Purpose.
+	LoginCodePurpose string
+	// NotificationChannel defines the channel an otp is delivered on.
+	NotificationChannel string
+	// DeliveryStatus defines model for DeliveryReceipt.Status.
+	DeliveryStatus string
+)
 
 type (
-	// LoginCode defines model for LoginCode.
+	// LoginCode defines model for LoginCode. Code holds the keyed hash of the otp, never the otp itself.
+	// Target holds the value being verified when the purpose is not a login, e.g. the new email address.
 	LoginCode struct {
 		Base
-		Username     string          `db:"username" json:"-"`
-		Code         string          `db:"code" json:"-"`
-		ExpiryTime   time.Time       `db:"expiry_time" json:"-"`
-		Status       LoginCodeStatus `db:"status" json:"-"`
-		ResponseMeta *string         `db:"response_meta" json:"-"`
+		Username     string           `db:"username" json:"-"`
+		Code         string           `db:"code" json:"-"`
+		ExpiryTime   time.Time        `db:"expiry_time" json:"-"`
+		Status       LoginCodeStatus  `db:"status" json:"-"`
+		Purpose      LoginCodePurpose `db:"purpose" json:"-"`
+		Target       *string          `db:"target" json:"-"`
+		Attempts     int              `db:"attempts" json:"-"`
+		LockedUntil  *time.Time       `db:"locked_until" json:"-"`
+		LastSentAt   *time.Time       `db:"last_sent_at" json:"-"`
+		MessageID    *string          `db:"message_id" json:"-"`
+		ResponseMeta *string          `db:"response_meta" json:"-"`
 		BaseAudit
 		DeletedAt *time.Time `db:"deleted_at" json:"-"`
 	} // @name LoginCode
 	// OtpMessage defines model for OtpMessage.
+	//
+	// Body and Subject replace the otp text for other notifications, such as security alerts.
 	OtpMessage struct {
-		To  string `json:"-"`
-		Otp string `json:"-"`
+		To      string              `json:"-"`
+		Otp     string              `json:"-"`
+		Subject string              `json:"-"`
+		Body    string              `json:"-"`
+		Channel NotificationChannel `json:"-"`
 	} // @name OtpMessage
+	// DeliveryReceipt defines model for DeliveryReceipt. It is stored as JSON in LoginCode.ResponseMeta.
+	DeliveryReceipt struct {
+		Channel        NotificationChannel `json:"channel" example:"SMS"`
+		Provider       string              `json:"provider,omitempty" example:"twilio"`
+		MessageID      string              `json:"message_id,omitempty" example:"SM1234567890abcdef1234567890abcdef"`
+		Status         DeliveryStatus      `json:"status" example:"SENT"`
+		ProviderStatus string              `json:"provider_status,omitempty" example:"queued"`
+		Error          string              `json:"error,omitempty"`
+		Attempts       []DeliveryAttempt   `json:"attempts,omitempty"`
+		UpdatedAt      time.Time           `json:"updated_at"`
+	} // @name DeliveryReceipt
+	// DeliveryAttempt defines model for DeliveryAttempt.
+	DeliveryAttempt struct {
+		Provider  string    `json:"provider" example:"twilio"`
+		Attempt   int       `json:"attempt" example:"1"`
+		MessageID string    `json:"message_id,omitempty" example:"SM1234567890abcdef1234567890abcdef"`
+		Error     string    `json:"error,omitempty"`
+		At        time.Time `json:"at"`
+	} // @name DeliveryAttempt
+	// OtpDelivery defines model for OtpDelivery, the delivery state of a code sent to a user.
+	// ClosedAt is set once the code was used or replaced.
+	OtpDelivery struct {
+		Purpose    LoginCodePurpose `json:"purpose" example:"LOGIN"`
+		Status     LoginCodeStatus  `json:"status" example:"PENDING"`
+		ExpiryTime time.Time        `json:"expiry_time"`
+		LastSentAt *time.Time       `json:"last_sent_at,omitempty"`
+		ClosedAt   *time.Time       `json:"closed_at,omitempty"`
+		Receipt    *DeliveryReceipt `json:"receipt,omitempty"`
+	} // @name OtpDelivery
 )
 
 type (
@@ -34,16 +83,28 @@ type (
 	LoginCodeRepository interface {
 		// FindByID returns a record by id
 		FindByID(ctx context.Context, id uuid.UUID) (result LoginCode, err error)
-		// FindByUsername returns a record by username
-		FindByUsername(ctx context.Context, username string) (result LoginCode, err error)
+		// FindAllByUsername returns the most recent records of the username for every purpose, used ones included
+		FindAllByUsername(ctx context.Context, username string) (result []LoginCode, err error)
+		// FindByUsername returns the record of the username for the given purpose
+		FindByUsername(ctx context.Context, username string, purpose LoginCodePurpose) (result LoginCode, err error)
 		// Create creates a new record
 		Create(ctx context.Context, entity *LoginCode) (err error)
 		// Update updates an existing record
 		Update(ctx context.Context, id uuid.UUID, entity *LoginCode) (err error)
+		// FindByMessageID returns the record of the message sent by a provider, used ones included
+		FindByMessageID(ctx context.Context, messageID string) (result LoginCode, err error)
+		// UpdateDeliveryReceipt stores the delivery receipt of a record and the id of its message
+		UpdateDeliveryReceipt(ctx context.Context, id uuid.UUID, messageID *string, meta string) (err error)
+		// IncrementAttempts increments the failed attempts of a record and returns the new count
+		IncrementAttempts(ctx context.Context, id uuid.UUID) (attempts int, err error)
+		// Consume marks a pending record as used, so its otp cannot be used again. It returns DataNotFoundError when
+		// the record is no longer pending, e.g. because a concurrent request consumed it first.
+		Consume(ctx context.Context, id uuid.UUID) (err error)
 		// Delete deletes an existing record by id
 		Delete(ctx context.Context, id uuid.UUID) (err error)
-		// DeleteByUsername deletes login codes by username.
-		DeleteByUsername(ctx context.Context, username string) (err error)
+		// DeleteByUsername soft-deletes the pending login codes of the username for the given purpose, their
+		// delivery receipts are kept.
+		DeleteByUsername(ctx context.Context, username string, purpose LoginCodePurpose) (err error)
 	}
 )
 
@@ -52,3 +113,28 @@ const (
 	LoginCodeStatusSUCCESS LoginCodeStatus = "SUCCESS"
 	LoginCodeStatusFAILED  LoginCodeStatus = "FAILED"
 )
+
+const (
+	LoginCodePurposeLOGIN              LoginCodePurpose = "LOGIN"
+	LoginCodePurposeEMAIL_VERIFICATION LoginCodePurpose = "EMAIL_VERIFICATION"
+	LoginCodePurposeSTEP_UP            LoginCodePurpose = "STEP_UP"
+	LoginCodePurposePHONE_CHANGE_OLD   LoginCodePurpose = "PHONE_CHANGE_OLD"
+	LoginCodePurposePHONE_CHANGE_NEW   LoginCodePurpose = "PHONE_CHANGE_NEW"
+)
+
+const (
+	NotificationChannelSMS   NotificationChannel = "SMS"
+	NotificationChannelEMAIL NotificationChannel = "EMAIL"
+)
+
+const (
+	DeliveryStatusSENT        DeliveryStatus = "SENT"
+	DeliveryStatusFAILED      DeliveryStatus = "FAILED"
+	DeliveryStatusDELIVERED   DeliveryStatus = "DELIVERED"
+	DeliveryStatusUNDELIVERED DeliveryStatus = "UNDELIVERED"
+)
+
+// IsFinal returns true when the status can no longer change
+func (s DeliveryStatus) IsFinal() bool {
+	return s == DeliveryStatusDELIVERED || s == DeliveryStatusUNDELIVERED
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// LoginEventOutcome defines the outcome of an authentication attempt
+	LoginEventOutcome string // @name LoginEventOutcome
+)
+
+const (
+	LoginEventOutcomeOTP_SENT       LoginEventOutcome = "OTP_SENT"
+	LoginEventOutcomeLINK_SENT      LoginEventOutcome = "LINK_SENT"
+	LoginEventOutcomeSUCCESS        LoginEventOutcome = "SUCCESS"
+	LoginEventOutcomeWRONG_OTP      LoginEventOutcome = "WRONG_OTP"
+	LoginEventOutcomeEXPIRED        LoginEventOutcome = "EXPIRED"
+	LoginEventOutcomeINVALID_LINK   LoginEventOutcome = "INVALID_LINK"
+	LoginEventOutcomeLOCKED         LoginEventOutcome = "LOCKED"
+	LoginEventOutcomeINACTIVE       LoginEventOutcome = "INACTIVE"
+	LoginEventOutcomeMFA_REQUIRED   LoginEventOutcome = "MFA_REQUIRED"
+	LoginEventOutcomeWRONG_MFA_CODE LoginEventOutcome = "WRONG_MFA_CODE"
+	LoginEventOutcomeRATE_LIMITED   LoginEventOutcome = "RATE_LIMITED"
+	LoginEventOutcomeFAILED         LoginEventOutcome = "FAILED"
+)
+
+type (
+	// LoginEvent defines model for LoginEvent. Events are never updated, so they only carry their creation time.
+	LoginEvent struct {
+		Base
+		UserID    *uuid.UUID        `db:"user_id" json:"user_id,omitempty"`
+		Username  string            `db:"username" json:"username" example:"+919876543210"`
+		Outcome   LoginEventOutcome `db:"outcome" json:"outcome" example:"SUCCESS"`
+		Channel   *string           `db:"channel" json:"channel,omitempty" example:"SMS"`
+		IPAddress *string           `db:"ip_address" json:"ip_address,omitempty" example:"203.0.113.10"`
+		UserAgent *string           `db:"user_agent" json:"user_agent,omitempty"`
+		RequestID *string           `db:"request_id" json:"request_id,omitempty" example:"kCzX1oP4HbYp2mJvQ7rTn9aLd3sW6uEe"`
+		CreatedAt time.Time         `db:"created_at" json:"created_at"`
+	} // @name LoginEvent
+)
+
+type (
+	// LoginEventFilter define the filters of the login events, empty fields match every event
+	LoginEventFilter struct {
+		UserID    *uuid.UUID
+		Username  string
+		Outcome   LoginEventOutcome
+		IPAddress string
+		From      time.Time
+		To        time.Time
+		Page      int64
+		Size      int64
+	} // @name LoginEventFilter
+)
+
+type (
+	// LoginEventRepository defines the methods that any login-event repository should implement.
+	LoginEventRepository interface {
+		// Create creates a new record
+		Create(ctx context.Context, entity *LoginEvent) (err error)
+		// FindAll returns a page of the events matching the filter, most recent first, and the number of matching events
+		FindAll(ctx context.Context, filter LoginEventFilter) (result []LoginEvent, total int64, err error)
+	}
+)
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// LoginMode defines how InitLogin lets the user prove they own the account
+	LoginMode string // @name LoginMode
+)
+
+const (
+	LoginModeOTP        LoginMode = "OTP"
+	LoginModeMAGIC_LINK LoginMode = "MAGIC_LINK"
+)
+
+type (
+	// MagicLink defines model for MagicLink.
+	//
+	// Only hashes are stored: TokenHash of the token in the link, BindingHash of the binding token returned to the
+	// client that requested the link and Fingerprint of its user agent.
+	MagicLink struct {
+		Base
+		UserID      uuid.UUID           `db:"user_id" json:"-"`
+		TokenHash   string              `db:"token_hash" json:"-"`
+		BindingHash string              `db:"binding_hash" json:"-"`
+		Fingerprint string              `db:"fingerprint" json:"-"`
+		Channel     NotificationChannel `db:"channel" json:"-"`
+		ExpiresAt   time.Time           `db:"expires_at" json:"-"`
+		UsedAt      *time.Time          `db:"used_at" json:"-"`
+		BaseAudit
+	} // @name MagicLink
+)
+
+type (
+	// MagicLinkLoginInput define the module for the MagicLinkLoginInput
+	MagicLinkLoginInput struct {
+		Token        string `json:"token" validate:"required" example:"8b7f1c2e-4a3d-4e5f-9a8b-7c6d5e4f3a2b.4f1a..."`
+		BindingToken string `json:"binding_token" validate:"required" example:"d3c2b1a0-9f8e-4d7c-8b6a-5f4e3d2c1b0a"`
+		TotpCode     string `json:"totp_code,omitempty" example:"654321"`
+		RecoveryCode string `json:"recovery_code,omitempty" example:"k7fq-2m9x"`
+		Device       string `json:"device" example:"Chrome on macOS"`
+		DeviceID     string `json:"device_id" validate:"max=128" example:"3f1c2a9e-7b44-4b8e-9d0a-5c6f1e2d3b4a"`
+		ClientInfo
+	} // @name MagicLinkLoginInput
+)
+
+type (
+	// MagicLinkRepository defines the methods that any magic-link repository should implement.
+	MagicLinkRepository interface {
+		// FindByTokenHash returns a magic link by the hash of its token
+		FindByTokenHash(ctx context.Context, hash string) (result MagicLink, err error)
+		// Create creates a new record
+		Create(ctx context.Context, entity *MagicLink) (err error)
+		// MarkUsed marks an unused link as used, it returns DataNotFoundError when the link was already used
+		MarkUsed(ctx context.Context, id uuid.UUID) (err error)
+		// ExpireByUserID marks every unused link of the user as used, so only the latest link works
+		ExpireByUserID(ctx context.Context, userID uuid.UUID) (err error)
+	}
+)
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// RecoveryCode defines model for RecoveryCode. CodeHash holds the keyed hash of the code, never the code itself.
+	RecoveryCode struct {
+		Base
+		UserID   uuid.UUID  `db:"user_id" json:"-"`
+		CodeHash string     `db:"code_hash" json:"-"`
+		UsedAt   *time.Time `db:"used_at" json:"-"`
+		BaseAudit
+	} // @name RecoveryCode
+)
+
+type (
+	// TotpEnrollmentOutput define the module for the TotpEnrollmentOutput
+	TotpEnrollmentOutput struct {
+		Secret          string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+		ProvisioningURI string `json:"provisioning_uri" example:"otpauth://totp/weCredit:%2B919876543210?algorithm=SHA1&digits=6&issuer=weCredit&period=30&secret=JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	} // @name TotpEnrollmentOutput
+	// MfaCodeInput define the module for the MfaCodeInput, either a totp code or a recovery code
+	MfaCodeInput struct {
+		ID           uuid.UUID `json:"-"`
+		TotpCode     string    `json:"totp_code" validate:"required_without=RecoveryCode" example:"123456"`
+		RecoveryCode string    `json:"recovery_code" example:"k7fq-2m9x"`
+	} // @name MfaCodeInput
+	// RecoveryCodesOutput define the module for the RecoveryCodesOutput
+	RecoveryCodesOutput struct {
+		RecoveryCodes []string `json:"recovery_codes" example:"k7fq-2m9x,p3dw-8hzr"`
+	} // @name RecoveryCodesOutput
+)
+
+type (
+	// RecoveryCodeRepository defines the methods that any recovery-code repository should implement.
+	RecoveryCodeRepository interface {
+		// Create creates a new record
+		Create(ctx context.Context, entity *RecoveryCode) (err error)
+		// Use marks the unused code of the user with the given hash as used, it returns DataNotFoundError when there is none
+		Use(ctx context.Context, userID uuid.UUID, codeHash string) (err error)
+		// DeleteByUserID deletes every code of the user
+		DeleteByUserID(ctx context.Context, userID uuid.UUID) (err error)
+	}
+
+	// MfaService defines the methods that any multi-factor authentication service should implement
+	MfaService interface {
+		// EnrollTotp creates a new totp secret for the user, it is only enabled once confirmed
+		EnrollTotp(userID uuid.UUID) (result TotpEnrollmentOutput, err error)
+		// ConfirmTotp enables the pending totp secret and returns the recovery codes of the user
+		ConfirmTotp(input MfaCodeInput) (result RecoveryCodesOutput, err error)
+		// DisableTotp disables the totp second factor of the user and deletes the recovery codes
+		DisableTotp(input MfaCodeInput) (err error)
+		// RegenerateRecoveryCodes replaces the recovery codes of the user
+		RegenerateRecoveryCodes(input MfaCodeInput) (result RecoveryCodesOutput, err error)
+		// VerifySecondFactor checks a totp code or a recovery code of a user with totp enabled
+		VerifySecondFactor(user User, totpCode, recoveryCode string) (err error)
+	}
+)
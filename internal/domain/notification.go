@@ -0,0 +1,27 @@
+package domain
+
+type (
+	// DeliveryStatusInput defines the module for the DeliveryStatusInput, a delivery report posted by a provider
+	DeliveryStatusInput struct {
+		Provider       string `json:"-"`
+		MessageID      string `json:"-"`
+		ProviderStatus string `json:"-"`
+		ErrorCode      string `json:"-"`
+		To             string `json:"-"`
+	} // @name DeliveryStatusInput
+	// WebhookDeliveryStatusInput define the module for the WebhookDeliveryStatusInput
+	WebhookDeliveryStatusInput struct {
+		MessageID string `json:"message_id" validate:"required" example:"b1946ac9"`
+		Status    string `json:"status" validate:"required" example:"delivered"`
+		ErrorCode string `json:"error_code" example:"30003"`
+		To        string `json:"to" example:"+919876543210"`
+	} // @name WebhookDeliveryStatusInput
+)
+
+type (
+	// NotificationService defines the methods that any notification service should implement
+	NotificationService interface {
+		// UpdateDeliveryStatus applies a delivery report to the login code of its message
+		UpdateDeliveryStatus(input DeliveryStatusInput) (err error)
+	}
+)
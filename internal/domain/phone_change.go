@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// PhoneChangeMethod defines how the ownership of the numbers was proven for a phone number change
+	PhoneChangeMethod string // @name PhoneChangeMethod
+)
+
+const (
+	// PhoneChangeMethodSELF means the user verified both the old and the new number
+	PhoneChangeMethodSELF PhoneChangeMethod = "SELF"
+	// PhoneChangeMethodADMIN means an admin vouched for the user, who only verified the new number
+	PhoneChangeMethodADMIN PhoneChangeMethod = "ADMIN"
+)
+
+type (
+	// PhoneChange defines model for PhoneChange, the audit record of a changed mobile number.
+	// Records are never updated, so they only carry their creation time.
+	PhoneChange struct {
+		Base
+		UserID      uuid.UUID         `db:"user_id" json:"user_id"`
+		OldUserName string            `db:"old_user_name" json:"old_user_name" example:"+919876543210"`
+		NewUserName string            `db:"new_user_name" json:"new_user_name" example:"+919812345678"`
+		Method      PhoneChangeMethod `db:"method" json:"method" example:"SELF"`
+		ActorID     *uuid.UUID        `db:"actor_id" json:"actor_id,omitempty"`
+		Reason      *string           `db:"reason" json:"reason,omitempty"`
+		IPAddress   *string           `db:"ip_address" json:"ip_address,omitempty"`
+		UserAgent   *string           `db:"user_agent" json:"user_agent,omitempty"`
+		RequestID   *string           `db:"request_id" json:"request_id,omitempty"`
+		CreatedAt   time.Time         `db:"created_at" json:"created_at"`
+	} // @name PhoneChange
+)
+
+type (
+	// ChangePhoneInput define the module for the ChangePhoneInput. AdminAssisted skips the code to the old number.
+	ChangePhoneInput struct {
+		ID            uuid.UUID `json:"-"`
+		ActorID       uuid.UUID `json:"-"`
+		UserName      string    `json:"user_name" validate:"required,e164" example:"+919812345678"`
+		AdminAssisted bool      `json:"-"`
+	} // @name ChangePhoneInput
+	// VerifyPhoneChangeInput define the module for the VerifyPhoneChangeInput
+	VerifyPhoneChangeInput struct {
+		ID            uuid.UUID `json:"-"`
+		ActorID       uuid.UUID `json:"-"`
+		OldOtp        string    `json:"old_otp" example:"123456"`
+		NewOtp        string    `json:"new_otp" validate:"required" example:"654321"`
+		Reason        string    `json:"reason" validate:"max=500" example:"Lost the SIM of the old number"`
+		AdminAssisted bool      `json:"-"`
+		ClientInfo
+	} // @name VerifyPhoneChangeInput
+)
+
+type (
+	// PhoneChangeRepository defines the methods that any phone-change repository should implement.
+	PhoneChangeRepository interface {
+		// Create creates a new record
+		Create(ctx context.Context, entity *PhoneChange) (err error)
+	}
+)
@@ -0,0 +1,147 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// Theme defines the color theme of the apps
+	Theme string // @name Theme
+)
+
+const (
+	ThemeLIGHT  Theme = "LIGHT"
+	ThemeDARK   Theme = "DARK"
+	ThemeSYSTEM Theme = "SYSTEM"
+)
+
+// supportedLanguages lists the ISO 639-1 codes of the languages the apps are translated to
+var supportedLanguages = []string{"en", "hi", "bn", "gu", "kn", "ml", "mr", "pa", "ta", "te"}
+
+type (
+	// UserPreferences defines model for UserPreferences. They are stored as a JSONB object on the user,
+	// missing keys take their default value.
+	UserPreferences struct {
+		Language             string                `json:"language" example:"en"`
+		NotificationChannels []NotificationChannel `json:"notification_channels" example:"SMS,EMAIL"`
+		MarketingOptIn       bool                  `json:"marketing_opt_in" example:"false"`
+		Theme                Theme                 `json:"theme" example:"SYSTEM"`
+	} // @name UserPreferences
+	// UpdatePreferencesInput define the module for the UpdatePreferencesInput. With Merge the preferences are
+	// a JSON merge patch applied to the stored ones, otherwise they replace them.
+	UpdatePreferencesInput struct {
+		ID          uuid.UUID
+		Preferences JSONB
+		Merge       bool
+	}
+)
+
+// DefaultUserPreferences returns the preferences of a user who has not changed any
+func DefaultUserPreferences() UserPreferences {
+	return UserPreferences{
+		Language:             "en",
+		NotificationChannels: []NotificationChannel{NotificationChannelSMS},
+		MarketingOptIn:       false,
+		Theme:                ThemeSYSTEM,
+	}
+}
+
+// ParseUserPreferences checks the keys and values of submitted preferences and fills in the defaults.
+// It returns a ValidationError listing every problem.
+func ParseUserPreferences(raw JSONB) (result UserPreferences, err error) {
+	fields := make([]string, 0)
+	for key := range raw {
+		switch key {
+		case "language", "notification_channels", "marketing_opt_in", "theme":
+		default:
+			fields = append(fields, fmt.Sprintf("%s is not a known preference", key))
+		}
+	}
+	result = DefaultUserPreferences()
+	if len(fields) == 0 {
+		b, err := json.Marshal(raw)
+		if err != nil {
+			return result, err
+		}
+		err = json.Unmarshal(b, &result)
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			fields = append(fields, fmt.Sprintf("%s has an invalid type", typeErr.Field))
+		} else if err != nil {
+			return result, err
+		}
+		// An explicit null resets the channels like a missing key
+		if result.NotificationChannels == nil {
+			result.NotificationChannels = DefaultUserPreferences().NotificationChannels
+		}
+	}
+	if len(fields) == 0 {
+		fields = result.check()
+	}
+	if len(fields) > 0 {
+		// Map iteration order is random, keep the messages stable
+		sort.Strings(fields)
+		return result, ValidationError{Code: ErrorCodeVALIDATION_ERROR, Message: MessageVALIDATIONFAILED, Fields: fields}
+	}
+	return result, nil
+}
+
+// ParseStoredUserPreferences reads the preferences stored on the user. Keys that are no longer known or whose
+// value is no longer valid, e.g. a language that was dropped, are ignored so they take their default value.
+func ParseStoredUserPreferences(raw JSONB) (result UserPreferences) {
+	valid := make(JSONB, len(raw))
+	for key, value := range raw {
+		if _, err := ParseUserPreferences(JSONB{key: value}); err == nil {
+			valid[key] = value
+		}
+	}
+	// Every key is valid on its own and no rule spans keys, so the parse cannot fail
+	result, err := ParseUserPreferences(valid)
+	if err != nil {
+		return DefaultUserPreferences()
+	}
+	return result
+}
+
+// JSONB returns the preferences as stored on the user
+func (p UserPreferences) JSONB() (result JSONB, err error) {
+	b, err := json.Marshal(p)
+	if err != nil {
+		return result, err
+	}
+	err = json.Unmarshal(b, &result)
+	return result, err
+}
+
+// check returns a message for every invalid value
+func (p UserPreferences) check() (fields []string) {
+	valid := false
+	for _, lang := range supportedLanguages {
+		valid = valid || p.Language == lang
+	}
+	if !valid {
+		fields = append(fields, fmt.Sprintf("language must be one of %s", strings.Join(supportedLanguages, " ")))
+	}
+	seen := make(map[NotificationChannel]bool, len(p.NotificationChannels))
+	for _, ch := range p.NotificationChannels {
+		if ch != NotificationChannelSMS && ch != NotificationChannelEMAIL {
+			fields = append(fields, "notification_channels must only contain SMS EMAIL")
+			break
+		}
+		if seen[ch] {
+			fields = append(fields, "notification_channels must not contain duplicates")
+			break
+		}
+		seen[ch] = true
+	}
+	if p.Theme != ThemeLIGHT && p.Theme != ThemeDARK && p.Theme != ThemeSYSTEM {
+		fields = append(fields, "theme must be one of LIGHT DARK SYSTEM")
+	}
+	return fields
+}
@@ -0,0 +1,17 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// RateLimitRepository defines the methods that any rate-limit repository should implement.
+	RateLimitRepository interface {
+		// Hit records a hit for the key when fewer than limit hits happened within the window.
+		// When the hit is not allowed, retryAfter tells how long until the next hit would be.
+		Hit(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error)
+		// DeleteBefore deletes the hits of every key older than the given time
+		DeleteBefore(ctx context.Context, before time.Time) (err error)
+	}
+)
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// RevokedToken defines model for RevokedToken.
+	RevokedToken struct {
+		TokenID   string    `db:"jti" json:"-"`
+		UserID    uuid.UUID `db:"user_id" json:"-"`
+		ExpiresAt time.Time `db:"expires_at" json:"-"`
+		CreatedAt time.Time `db:"created_at" json:"-"`
+	} // @name RevokedToken
+)
+
+type (
+	// RevokedTokenRepository defines the methods that any revoked-token repository should implement.
+	RevokedTokenRepository interface {
+		// Create revokes a token
+		Create(ctx context.Context, entity *RevokedToken) (err error)
+		// Exists reports whether the token with the given id is revoked
+		Exists(ctx context.Context, tokenID string) (result bool, err error)
+		// DeleteExpired deletes the records of tokens that have expired anyway
+		DeleteExpired(ctx context.Context) (err error)
+	}
+)
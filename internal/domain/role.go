@@ -0,0 +1,66 @@
+package domain
+
+// Permission defines an action a role is allowed to perform.
+type Permission string
+
+const (
+	UserRoleADMIN        UserRole = "ADMIN"
+	UserRoleLOAN_OFFICER UserRole = "LOAN_OFFICER"
+	UserRoleSUPPORT      UserRole = "SUPPORT"
+	UserRoleUSER         UserRole = "USER"
+)
+
+const (
+	// PermissionUSERS_READ allows reading the resources of any user
+	PermissionUSERS_READ Permission = "users:read"
+	// PermissionUSERS_WRITE allows modifying the resources of any user
+	PermissionUSERS_WRITE Permission = "users:write"
+	// PermissionUSERS_MANAGE_ROLES allows changing the role of any user
+	PermissionUSERS_MANAGE_ROLES Permission = "users:manage_roles"
+	// PermissionUSERS_MANAGE_STATUS allows suspending, reactivating, deleting and restoring any user
+	PermissionUSERS_MANAGE_STATUS Permission = "users:manage_status"
+)
+
+// rolePermissions defines the permissions granted to every role
+var rolePermissions = map[UserRole][]Permission{
+	UserRoleADMIN: {
+		PermissionUSERS_READ,
+		PermissionUSERS_WRITE,
+		PermissionUSERS_MANAGE_ROLES,
+		PermissionUSERS_MANAGE_STATUS,
+	},
+	UserRoleLOAN_OFFICER: {
+		PermissionUSERS_READ,
+	},
+	UserRoleSUPPORT: {
+		PermissionUSERS_READ,
+	},
+	UserRoleUSER: {},
+}
+
+// mfaRoles lists the high-privilege roles whose permissions are only granted to tokens issued after a second factor
+var mfaRoles = map[UserRole]bool{
+	UserRoleADMIN:        true,
+	UserRoleLOAN_OFFICER: true,
+}
+
+// RequiresMfa reports whether the permissions of the role require a second factor
+func (r UserRole) RequiresMfa() bool {
+	return mfaRoles[r]
+}
+
+// IsValid reports whether the role is known
+func (r UserRole) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// HasPermission reports whether the role grants the permission
+func (r UserRole) HasPermission(p Permission) bool {
+	for _, granted := range rolePermissions[r] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
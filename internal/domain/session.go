@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// Session defines model for Session.
+	//
+	// Every refresh token belongs to a session. Rotating a refresh token creates a new session in the same family.
+	Session struct {
+		Base
+		UserID           uuid.UUID  `db:"user_id" json:"user_id"`
+		FamilyID         uuid.UUID  `db:"family_id" json:"-"`
+		UserDeviceID     *uuid.UUID `db:"user_device_id" json:"-"`
+		RefreshTokenHash string     `db:"refresh_token_hash" json:"-"`
+		Device           *string    `db:"device" json:"device,omitempty" example:"Pixel 8"`
+		IPAddress        *string    `db:"ip_address" json:"ip_address,omitempty" example:"203.0.113.10"`
+		UserAgent        *string    `db:"user_agent" json:"user_agent,omitempty"`
+		Mfa              bool       `db:"mfa" json:"-"`
+		AuthTime         *time.Time `db:"auth_time" json:"-"`
+		ExpiresAt        time.Time  `db:"expires_at" json:"expires_at"`
+		RotatedAt        *time.Time `db:"rotated_at" json:"-"`
+		RevokedAt        *time.Time `db:"revoked_at" json:"-"`
+		BaseAudit
+	} // @name Session
+)
+
+type (
+	// RefreshTokenInput defines the model for the RefreshTokenInput
+	RefreshTokenInput struct {
+		RefreshToken string `json:"refresh_token" validate:"required"`
+		ClientInfo
+	} // @name RefreshTokenInput
+)
+
+type (
+	// SessionRepository defines the methods that any session repository should implement.
+	SessionRepository interface {
+		// FindByRefreshTokenHash returns a session by the hash of its refresh token
+		FindByRefreshTokenHash(ctx context.Context, hash string) (result Session, err error)
+		// Create creates a new session
+		Create(ctx context.Context, entity *Session) (err error)
+		// MarkRotated marks an active session as rotated, it returns DataNotFoundError when the session was already used
+		MarkRotated(ctx context.Context, id uuid.UUID) (err error)
+		// UpdateAuthTime sets the authentication time of the active session of a token family
+		UpdateAuthTime(ctx context.Context, familyID uuid.UUID, at time.Time) (err error)
+		// RevokeFamily revokes every session of a token family
+		RevokeFamily(ctx context.Context, familyID uuid.UUID) (err error)
+		// RevokeByUserDeviceID revokes every session started on the device
+		RevokeByUserDeviceID(ctx context.Context, userDeviceID uuid.UUID) (err error)
+		// RevokeByUserID revokes every session of the user
+		RevokeByUserID(ctx context.Context, userID uuid.UUID) (err error)
+	}
+)
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"github.com/gofrs/uuid/v5"
+)
+
+type (
+	// StepUpChallengeInput define the module for the StepUpChallengeInput
+	StepUpChallengeInput struct {
+		ID      uuid.UUID           `json:"-"`
+		Channel NotificationChannel `json:"channel" validate:"omitempty,oneof=SMS EMAIL" example:"SMS"`
+	} // @name StepUpChallengeInput
+	// StepUpVerifyInput define the module for the StepUpVerifyInput. SessionID and Mfa are carried over from the current token.
+	StepUpVerifyInput struct {
+		ID           uuid.UUID `json:"-"`
+		SessionID    uuid.UUID `json:"-"`
+		Mfa          bool      `json:"-"`
+		Otp          string    `json:"otp" validate:"required" example:"123456"`
+		TotpCode     string    `json:"totp_code,omitempty" example:"654321"`
+		RecoveryCode string    `json:"recovery_code,omitempty" example:"k7fq-2m9x"`
+	} // @name StepUpVerifyInput
+	// StepUpOutput define the module for the StepUpOutput
+	StepUpOutput struct {
+		Token     string `json:"token"`
+		ExpiresIn int64  `json:"expires_in"`
+		AuthTime  int64  `json:"auth_time" example:"1735812000"`
+	} // @name StepUpOutput
+)
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 )
@@ -9,14 +10,44 @@ import (
 type (
 	// UserRole represents the role a user in the system.
 	UserRole string
+	// UserSortField defines a field users can be sorted by
+	UserSortField string
 )
+
+const (
+	UserSortFieldCREATED_AT UserSortField = "created_at"
+	UserSortFieldUPDATED_AT UserSortField = "updated_at"
+	UserSortFieldFULL_NAME  UserSortField = "full_name"
+	UserSortFieldUSER_NAME  UserSortField = "user_name"
+)
+
+// IsValid reports whether users can be sorted by the field
+func (f UserSortField) IsValid() bool {
+	switch f {
+	case UserSortFieldCREATED_AT, UserSortFieldUPDATED_AT, UserSortFieldFULL_NAME, UserSortFieldUSER_NAME:
+		return true
+	}
+	return false
+}
+
 type (
 	// User defines the module for User
 	User struct {
 		Base
-		UserName string `db:"user_name" json:"user_name,omitempty" example:"+919876543210"`
-		Role     string `db:"role" json:"role,omitempty"  example:"USER"`
-		FullName string `db:"full_name" json:"full_name,omitempty" example:"John Doe"`
+		UserName         string     `db:"user_name" json:"user_name,omitempty" example:"+919876543210"`
+		Role             string     `db:"role" json:"role,omitempty"  example:"USER"`
+		FullName         string     `db:"full_name" json:"full_name,omitempty" example:"John Doe"`
+		Email            *string    `db:"email" json:"email,omitempty" example:"john.doe@example.com"`
+		EmailVerifiedAt  *time.Time `db:"email_verified_at" json:"email_verified_at,omitempty"`
+		TotpSecret       *string    `db:"totp_secret" json:"-"`
+		TotpEnabledAt    *time.Time `db:"totp_enabled_at" json:"totp_enabled_at,omitempty"`
+		TotpLastStep     *int64     `db:"totp_last_step" json:"-"`
+		TokensValidAfter *time.Time `db:"tokens_valid_after" json:"-"`
+		Status           UserStatus `db:"status" json:"status,omitempty" example:"ACTIVE"`
+		StatusReason     *string    `db:"status_reason" json:"status_reason,omitempty" example:"Suspected account takeover"`
+		StatusChangedAt  *time.Time `db:"status_changed_at" json:"status_changed_at,omitempty"`
+		DeletedAt        *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+		Preferences      JSONB      `db:"preferences" json:"-"`
 		BaseAudit
 	} // @name User
 
@@ -27,41 +58,132 @@ type (
 	RegisterUserInput struct {
 		FullName string   `json:"full_name" example:"John Doe"`
 		UserName string   `json:"user_name" example:"+919876543210"`
-		Role     UserRole `json:"role" example:"RECEPTIONIST"`
+		Role     UserRole `json:"role" example:"USER"`
 	} // @name CreateUserInput
-	// UpdateUserInput define the module for the UpdateUserInput
+	// UpdateUserRoleInput define the module for the UpdateUserRoleInput
+	UpdateUserRoleInput struct {
+		ID      uuid.UUID `json:"-"`
+		ActorID uuid.UUID `json:"-"`
+		Role    UserRole  `json:"role" validate:"required,oneof=ADMIN LOAN_OFFICER SUPPORT USER" example:"LOAN_OFFICER"`
+	} // @name UpdateUserRoleInput
+	// UserFilter define the filters of the users, empty fields match every user.
+	//
+	// Deleted users only match when Status is DELETED. Search matches part of the name, the mobile number or the email.
+	UserFilter struct {
+		Role     UserRole
+		Status   UserStatus
+		Search   string
+		From     time.Time
+		To       time.Time
+		SortBy   UserSortField
+		SortDesc bool
+		Page     int64
+		Size     int64
+	} // @name UserFilter
+	// ChangeUserStatusInput define the module for the ChangeUserStatusInput
+	ChangeUserStatusInput struct {
+		ID      uuid.UUID  `json:"-"`
+		ActorID uuid.UUID  `json:"-"`
+		Status  UserStatus `json:"status" validate:"required,oneof=ACTIVE SUSPENDED DEACTIVATED DELETED" example:"SUSPENDED"`
+		Reason  string     `json:"reason" validate:"max=500" example:"Suspected account takeover"`
+	} // @name ChangeUserStatusInput
+	// UpdateUserInput define the module for the UpdateUserInput. Omitted fields are left unchanged.
+	//
+	// The mobile number, the email and the role have their own flows, so the fields are only decoded to reject them.
 	UpdateUserInput struct {
-		RegisterUserInput
+		ID       uuid.UUID `json:"-"`
+		FullName *string   `json:"full_name" validate:"omitempty,max=100" example:"John Doe"`
+		UserName *string   `json:"user_name" swaggerignore:"true"`
+		Email    *string   `json:"email" swaggerignore:"true"`
+		Role     *UserRole `json:"role" swaggerignore:"true"`
 	} // @name UpdateUserInput
 	// InitLoginInput define the module for the InitLoginInput
 	InitLoginInput struct {
-		UserName string `json:"username" example:"+919876543210"`
+		UserName string              `json:"username" example:"+919876543210"`
+		Channel  NotificationChannel `json:"channel" validate:"omitempty,oneof=SMS EMAIL" example:"SMS"`
+		Mode     LoginMode           `json:"mode" validate:"omitempty,oneof=OTP MAGIC_LINK" example:"OTP"`
+		ClientInfo
 	} // @name InitLoginInput
+	// InitLoginOutput define the module for the InitLoginOutput
+	InitLoginOutput struct {
+		ExpiresIn   int64 `json:"expires_in" example:"300"`
+		ResendAfter int64 `json:"resend_after" example:"30"`
+		// BindingToken must be sent back with the magic link, so the link only works on the client that requested it
+		BindingToken string `json:"binding_token,omitempty" example:"d3c2b1a0-9f8e-4d7c-8b6a-5f4e3d2c1b0a"`
+	} // @name InitLoginOutput
+	// UpdateEmailInput define the module for the UpdateEmailInput
+	UpdateEmailInput struct {
+		ID    uuid.UUID `json:"-"`
+		Email string    `json:"email" validate:"required,email" example:"john.doe@example.com"`
+	} // @name UpdateEmailInput
+	// VerifyEmailInput define the module for the VerifyEmailInput
+	VerifyEmailInput struct {
+		ID  uuid.UUID `json:"-"`
+		Otp string    `json:"otp" validate:"required" example:"123456"`
+	} // @name VerifyEmailInput
 	// LoginInput  define the module for the LoginInput
 	LoginInput struct {
-		UserName string `json:"username" example:"+919876543210"`
-		Otp      string `json:"otp" example:"123456"`
+		UserName     string `json:"username" example:"+919876543210"`
+		Otp          string `json:"otp" example:"123456"`
+		TotpCode     string `json:"totp_code,omitempty" example:"654321"`
+		RecoveryCode string `json:"recovery_code,omitempty" example:"k7fq-2m9x"`
+		Device       string `json:"device" example:"Pixel 8"`
+		DeviceID     string `json:"device_id" validate:"max=128" example:"3f1c2a9e-7b44-4b8e-9d0a-5c6f1e2d3b4a"`
+		ClientInfo
 	} // @name LoginInput
+	// LogoutInput define the module for the LogoutInput
+	LogoutInput struct {
+		UserID    uuid.UUID `json:"-"`
+		TokenID   string    `json:"-"`
+		SessionID uuid.UUID `json:"-"`
+		ExpiresAt time.Time `json:"-"`
+	} // @name LogoutInput
 	// LoginOutput define the module for the LoginOutput
 	LoginOutput struct {
-		Token     string `json:"token"`
-		ExpiresIn int64  `json:"expires_in"`
+		Token            string `json:"token"`
+		ExpiresIn        int64  `json:"expires_in"`
+		RefreshToken     string `json:"refresh_token"`
+		RefreshExpiresIn int64  `json:"refresh_expires_in" example:"2592000"`
 	} // @name LoginOutput
 )
 
 type (
 	// UserRepository defines the methods that any use repository should implements
 	UserRepository interface {
-		// FindByID return the user by id
+		// FindByID return the user by id, deleted users are not returned
 		FindByID(ctx context.Context, id uuid.UUID) (result User, err error)
-		// FindByUserName return the user by username
+		// FindByIDIncludingDeleted return the user by id, even when it was deleted
+		FindByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (result User, err error)
+		// FindByUserName return the user by username, deleted users are not returned
 		FindByUserName(ctx context.Context, username string) (result User, err error)
+		// FindByEmail return the live user by email, ignoring the case
+		FindByEmail(ctx context.Context, email string) (result User, err error)
+		// FindAll returns a page of the users matching the filter and the number of matching users
+		FindAll(ctx context.Context, filter UserFilter) (result []User, total int64, err error)
 		// CreateUser creates a new user
 		CreateUser(ctx context.Context, entity *User) (err error)
 		// UpdateUser updates the user
 		UpdateUser(ctx context.Context, entity *User) (err error)
-		// DeleteUser deletes the user
+		// UpdateRole updates the role of the user
+		UpdateRole(ctx context.Context, id uuid.UUID, role UserRole) (err error)
+		// UpdateFullName updates the full name of the user
+		UpdateFullName(ctx context.Context, id uuid.UUID, fullName string) (err error)
+		// FindPreferencesForUpdate returns the stored preferences of the user and locks the row until the transaction ends
+		FindPreferencesForUpdate(ctx context.Context, id uuid.UUID) (result JSONB, err error)
+		// UpdatePreferences replaces the preferences of the user
+		UpdatePreferences(ctx context.Context, id uuid.UUID, preferences JSONB) (err error)
+		// UpdateEmail sets the verified email of the user
+		UpdateEmail(ctx context.Context, id uuid.UUID, email string, verifiedAt time.Time) (err error)
+		// DeleteUser soft-deletes the user
 		DeleteUser(ctx context.Context, id uuid.UUID) (err error)
+		// UpdateStatus moves the user from the given status to a new one, it returns DataNotFoundError when the user is no longer in the given status
+		UpdateStatus(ctx context.Context, id uuid.UUID, from, to UserStatus, reason *string) (err error)
+		// UpdateTotp sets the encrypted totp secret of the user and when it was enabled, nil values disable totp
+		UpdateTotp(ctx context.Context, id uuid.UUID, secret *string, enabledAt *time.Time) (err error)
+		// UpdateTotpLastStep records the last time step a totp code was accepted for, it returns DataNotFoundError when the step was already used
+		UpdateTotpLastStep(ctx context.Context, id uuid.UUID, step int64) (err error)
+		// UpdateTokensValidAfter rejects the access tokens of the user issued before the given time
+		UpdateTokensValidAfter(ctx context.Context, id uuid.UUID, at time.Time) (err error)
 	}
 
 	// UserService defines the methods that any use service should implements
@@ -69,12 +191,54 @@ type (
 		// Login login the user
 		Login(input LoginInput) (result LoginOutput, err error)
 		// InitLogin init the login
-		InitLogin(input InitLoginInput) (err error)
+		InitLogin(input InitLoginInput) (result InitLoginOutput, err error)
+		// LoginWithMagicLink exchanges a magic link for a token
+		LoginWithMagicLink(input MagicLinkLoginInput) (result LoginOutput, err error)
+		// RefreshToken exchanges a refresh token for a new access token and a rotated refresh token
+		RefreshToken(input RefreshTokenInput) (result LoginOutput, err error)
+		// Logout revokes the access token and the session it belongs to
+		Logout(input LogoutInput) (err error)
+		// LogoutAll revokes every access token and session of the user
+		LogoutAll(userID uuid.UUID) (err error)
 		// RegisterUser register a new user
 		RegisterUser(input RegisterUserInput) (result User, err error)
 		// FindByUserName find the user by username
 		FindByUserName(username string) (result User, err error)
 		// FindByID find the user by id
 		FindByID(id uuid.UUID) (result User, err error)
+		// FindAll returns a page of the users matching the filter and the number of matching users
+		FindAll(filter UserFilter) (result []User, total int64, err error)
+		// UpdateProfile applies a partial update to the profile of the user
+		UpdateProfile(input UpdateUserInput) (result User, err error)
+		// FindPreferences returns the preferences of the user, with the defaults of the keys never set
+		FindPreferences(userID uuid.UUID) (result UserPreferences, err error)
+		// UpdatePreferences replaces or merge-patches the preferences of the user
+		UpdatePreferences(input UpdatePreferencesInput) (result UserPreferences, err error)
+		// UpdateRole changes the role of a user
+		UpdateRole(input UpdateUserRoleInput) (result User, err error)
+		// BootstrapAdmin makes the user with the mobile number an admin, creating the user when needed, as long as there is no admin yet. The user must be active
+		BootstrapAdmin(username string) (err error)
+		// ChangeStatus suspends, deactivates, deletes, reactivates or restores a user
+		ChangeStatus(input ChangeUserStatusInput) (result User, err error)
+		// RequestPhoneChange sends the codes that prove the user owns the current and the new mobile number
+		RequestPhoneChange(input ChangePhoneInput) (result InitLoginOutput, err error)
+		// VerifyPhoneChange checks the codes, changes the mobile number of the user and logs the user out everywhere
+		VerifyPhoneChange(input VerifyPhoneChangeInput) (result User, err error)
+		// RequestEmailVerification sends a verification code to the new email of the user
+		RequestEmailVerification(input UpdateEmailInput) (result InitLoginOutput, err error)
+		// VerifyEmail checks the verification code and sets the email of the user
+		VerifyEmail(input VerifyEmailInput) (result User, err error)
+		// FindOtpDeliveries returns the delivery state of the recent codes of the user, used ones included
+		FindOtpDeliveries(userID uuid.UUID) (result []OtpDelivery, err error)
+		// FindDevices returns the devices the user logged in from
+		FindDevices(userID uuid.UUID) (result []UserDevice, err error)
+		// RevokeDevice revokes a device of the user and the sessions started on it
+		RevokeDevice(input RevokeDeviceInput) (err error)
+		// FindLoginEvents returns a page of the login events matching the filter and the number of matching events
+		FindLoginEvents(filter LoginEventFilter) (result []LoginEvent, total int64, err error)
+		// StepUpChallenge sends an otp to the user to re-authenticate within the current session
+		StepUpChallenge(input StepUpChallengeInput) (result InitLoginOutput, err error)
+		// StepUpVerify checks the step-up otp and returns a token with a fresh authentication time
+		StepUpVerify(input StepUpVerifyInput) (result StepUpOutput, err error)
 	}
 )
@@ -0,0 +1,38 @@
+package domain
+
+type (
+	// UserStatus represents the lifecycle status of a user account.
+	UserStatus string // @name UserStatus
+)
+
+const (
+	UserStatusACTIVE      UserStatus = "ACTIVE"
+	UserStatusSUSPENDED   UserStatus = "SUSPENDED"
+	UserStatusDEACTIVATED UserStatus = "DEACTIVATED"
+	UserStatusDELETED     UserStatus = "DELETED"
+)
+
+// userStatusTransitions defines, for every status, the statuses a user can be moved to it from
+var userStatusTransitions = map[UserStatus][]UserStatus{
+	// Reactivating a suspended or deactivated user, or restoring a deleted one
+	UserStatusACTIVE:      {UserStatusSUSPENDED, UserStatusDEACTIVATED, UserStatusDELETED},
+	UserStatusSUSPENDED:   {UserStatusACTIVE, UserStatusDEACTIVATED},
+	UserStatusDEACTIVATED: {UserStatusACTIVE},
+	UserStatusDELETED:     {UserStatusACTIVE, UserStatusSUSPENDED, UserStatusDEACTIVATED},
+}
+
+// IsValid reports whether the status is known
+func (s UserStatus) IsValid() bool {
+	_, ok := userStatusTransitions[s]
+	return ok
+}
+
+// CanChangeTo reports whether a user with the status can be moved to the given status
+func (s UserStatus) CanChangeTo(to UserStatus) bool {
+	for _, from := range userStatusTransitions[to] {
+		if from == s {
+			return true
+		}
+	}
+	return false
+}
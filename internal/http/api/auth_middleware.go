@@ -0,0 +1,125 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/security"
+)
+
+// checkRevocation rejects access tokens that have been revoked, it must run after the jwt middleware
+func (b WeCreditApi) checkRevocation(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		claims, ok := security.GetTokenClaimsForContext(ctx)
+		if !ok {
+			return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+		}
+		revoked, err := b.rs.IsRevoked(ctx.Request().Context(), claims)
+		if err != nil {
+			return err
+		}
+		if revoked {
+			return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+		}
+		return next(ctx)
+	}
+}
+
+// requirePermissions allows the request only when the role of the token grants every given permission
+func requirePermissions(permissions ...domain.Permission) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			role := roleForContext(ctx)
+			for _, p := range permissions {
+				if !role.HasPermission(p) {
+					return domain.ForbiddenAccessError{Code: domain.ErrorCodeFORBIDDEN_ACCESS, Message: domain.MessageFORBIDDENACCESS}
+				}
+			}
+			if err := requireMfa(ctx, role); err != nil {
+				return err
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// requireOwnership allows the request only when the user id in the path param is the user of the token.
+//
+// Roles that grant the users:read permission may read, and roles that grant users:write may modify, any user,
+// once the token carries a second factor when the role requires one.
+// Every route registered below a group that uses this middleware inherits the check.
+func requireOwnership(param string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, ok := security.GetTokenClaimsForContext(ctx)
+			if !ok {
+				return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+			}
+			if ctx.Param(param) == claims.UserID {
+				return next(ctx)
+			}
+			override := domain.PermissionUSERS_WRITE
+			if method := ctx.Request().Method; method == http.MethodGet || method == http.MethodHead {
+				override = domain.PermissionUSERS_READ
+			}
+			if role := domain.UserRole(claims.Role); role.HasPermission(override) {
+				if err := requireMfa(ctx, role); err != nil {
+					return err
+				}
+				return next(ctx)
+			}
+			slog.Warn("access to another user's resource denied",
+				"user_id", claims.UserID,
+				"role", claims.Role,
+				"target_user_id", ctx.Param(param),
+				"method", ctx.Request().Method,
+				"path", ctx.Path(),
+				"request_id", ctx.Response().Header().Get(echo.HeaderXRequestID),
+			)
+			return domain.ForbiddenAccessError{Code: domain.ErrorCodeFORBIDDEN_ACCESS, Message: domain.MessageFORBIDDENACCESS}
+		}
+	}
+}
+
+// requireMfa returns an error when the role requires a second factor that the token was not issued with
+func requireMfa(ctx echo.Context, role domain.UserRole) error {
+	if !role.RequiresMfa() {
+		return nil
+	}
+	claims, ok := security.GetTokenClaimsForContext(ctx)
+	if ok && claims.Mfa {
+		return nil
+	}
+	return domain.ForbiddenAccessError{Code: domain.ErrorCodeMFA_REQUIRED, Message: domain.MessageMFAREQUIRED}
+}
+
+// requireRecentAuth allows the request only when the user authenticated within maxAge, at login or through a step-up
+func requireRecentAuth(maxAge time.Duration) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			claims, ok := security.GetTokenClaimsForContext(ctx)
+			if !ok {
+				return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+			}
+			// Tokens issued before auth_time existed never count as recent
+			if claims.AuthTime == 0 || time.Since(time.Unix(claims.AuthTime, 0)) > maxAge {
+				return domain.ForbiddenAccessError{Code: domain.ErrorCodeSTEP_UP_REQUIRED, Message: domain.MessageSTEPUPREQUIRED}
+			}
+			return next(ctx)
+		}
+	}
+}
+
+// roleForContext returns the role claim of the auth token
+func roleForContext(ctx echo.Context) domain.UserRole {
+	claims := security.GetClaimsForContext(ctx)
+	if claims == nil {
+		return ""
+	}
+	role, _ := claims["role"].(string)
+	return domain.UserRole(role)
+}
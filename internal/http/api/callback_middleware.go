@@ -0,0 +1,63 @@
+package api
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"io"
+	"log/slog"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/notification"
+)
+
+// verifyTwilioSignature rejects twilio callbacks whose X-Twilio-Signature does not match TWILIO_STATUS_CALLBACK_URL
+func (b WeCreditApi) verifyTwilioSignature(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		if b.cfg.AccountAuthToken == "" || b.cfg.TwilioStatusCallbackUrl == "" {
+			return rejectCallback(ctx, "twilio callbacks are not configured")
+		}
+		params, err := ctx.FormParams()
+		if err != nil {
+			return err
+		}
+		// Twilio signs the url it posted to, which is the configured one and not the one seen behind a proxy
+		expected := notification.TwilioSignature(b.cfg.AccountAuthToken, b.cfg.TwilioStatusCallbackUrl, params)
+		if !hmac.Equal([]byte(expected), []byte(ctx.Request().Header.Get("X-Twilio-Signature"))) {
+			return rejectCallback(ctx, "invalid twilio signature")
+		}
+		return next(ctx)
+	}
+}
+
+// verifyWebhookSignature rejects webhook callbacks whose body is not signed with NOTIFICATION_WEBHOOK_SECRET
+func (b WeCreditApi) verifyWebhookSignature(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		if b.cfg.NotificationWebhookSecret == "" {
+			return rejectCallback(ctx, "webhook callbacks require NOTIFICATION_WEBHOOK_SECRET")
+		}
+		body, err := io.ReadAll(ctx.Request().Body)
+		if err != nil {
+			return err
+		}
+		// Restore the body so the handler can decode it
+		ctx.Request().Body = io.NopCloser(bytes.NewReader(body))
+		expected := notification.WebhookSignature(b.cfg.NotificationWebhookSecret, body)
+		if !hmac.Equal([]byte(expected), []byte(ctx.Request().Header.Get(notification.SignatureHeader))) {
+			return rejectCallback(ctx, "invalid webhook signature")
+		}
+		return next(ctx)
+	}
+}
+
+// rejectCallback logs why a provider callback was refused and returns a forbidden error
+func rejectCallback(ctx echo.Context, reason string) error {
+	slog.Warn("provider callback rejected",
+		"reason", reason,
+		"path", ctx.Path(),
+		"remote_ip", ctx.RealIP(),
+		"request_id", ctx.Response().Header().Get(echo.HeaderXRequestID),
+	)
+	return domain.ForbiddenAccessError{Code: domain.ErrorCodeFORBIDDEN_ACCESS, Message: domain.MessageFORBIDDENACCESS}
+}
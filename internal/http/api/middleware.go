@@ -2,8 +2,11 @@ package api
 
 import (
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -22,9 +25,22 @@ func (b WeCreditApi) SetupMiddleware(e *echo.Echo) {
 	vv10.RegisterValidation("trim", func(fl validator.FieldLevel) bool {
 		return len(strings.TrimSpace(fl.Field().String())) != 0
 	})
+	vv10.RegisterValidation("date", func(fl validator.FieldLevel) bool {
+		_, err := time.Parse(domain.DateLayout, fl.Field().String())
+		return err == nil
+	})
+	vv10.RegisterValidation("pincode", func(fl validator.FieldLevel) bool {
+		return domain.IsValidPincode(fl.Field().String())
+	})
+	vv10.RegisterValidation("indian_state", func(fl validator.FieldLevel) bool {
+		_, ok := domain.IndianState(fl.Field().String())
+		return ok
+	})
 	e.Validator = &transport.CustomValidator{Validator: vv10}
 	// Set up the error handler middleware
 	e.HTTPErrorHandler = errorMiddleware
+	// Only trust X-Forwarded-For from proxies in private networks, so clients cannot spoof their IP
+	e.IPExtractor = echo.ExtractIPFromXFFHeader()
 	// Set the request body limit to 10M
 	e.Use(echomiddleware.BodyLimit("10M"))
 	// Recovery middleware recovers from panics anywhere in the chain,
@@ -75,29 +91,50 @@ func errorMiddleware(err error, c echo.Context) {
 		errs := err.(validator.ValidationErrors)
 
 		for _, e := range errs {
+			// Nested fields keep their parent, so the current and permanent address can be told apart
+			field := e.Field()
+			if i := strings.Index(e.Namespace(), "."); i >= 0 {
+				field = e.Namespace()[i+1:]
+			}
+
 			if e.Tag() == "required" {
 
-				fields = append(fields, fmt.Sprintf("%s is required", e.Field()))
+				fields = append(fields, fmt.Sprintf("%s is required", field))
 				continue
 			}
 
 			if e.Tag() == "e164" {
-				fields = append(fields, fmt.Sprintf("%s is an invalid mobile number", e.Field()))
+				fields = append(fields, fmt.Sprintf("%s is an invalid mobile number", field))
 				continue
 			}
 
 			if e.Tag() == "email" {
-				fields = append(fields, fmt.Sprintf("%s is an invalid email address", e.Field()))
+				fields = append(fields, fmt.Sprintf("%s is an invalid email address", field))
 				continue
 			}
 
 			if e.Tag() == "oneof" {
-				fields = append(fields, fmt.Sprintf("%s must be one of %s", e.Field(), e.Param()))
+				fields = append(fields, fmt.Sprintf("%s must be one of %s", field, e.Param()))
 				continue
 			}
 
 			if e.Tag() == "max" {
-				fields = append(fields, fmt.Sprintf("%s must not exceed %s characters", e.Field(), e.Param()))
+				fields = append(fields, fmt.Sprintf("%s must not exceed %s characters", field, e.Param()))
+				continue
+			}
+
+			if e.Tag() == "date" {
+				fields = append(fields, fmt.Sprintf("%s must be a date formatted as YYYY-MM-DD", field))
+				continue
+			}
+
+			if e.Tag() == "pincode" {
+				fields = append(fields, fmt.Sprintf("%s is an invalid pincode", field))
+				continue
+			}
+
+			if e.Tag() == "indian_state" {
+				fields = append(fields, fmt.Sprintf("%s is not a state or union territory of India", field))
 				continue
 			}
 
@@ -110,13 +147,24 @@ func errorMiddleware(err error, c echo.Context) {
 		}
 		_ = c.JSON(http.StatusBadRequest, ve)
 
+	case domain.ValidationError:
+		_ = c.JSON(http.StatusBadRequest, err)
+
 	case *pgconn.PgError:
+		// Repositories translate the errors a client can act on, the rest is logged but never returned as it names tables and constraints
+		slog.Error("unhandled database error", "code", err.(*pgconn.PgError).Code, "err", err)
 		res := domain.SystemError{
 			Code:    domain.ErrorCodeINTERNAL_SERVER_ERROR,
-			Message: err.Error(),
+			Message: domain.MessageSOMETHINGWENTWRONG,
 		}
 		_ = c.JSON(http.StatusInternalServerError, res)
 
+	case domain.ConflictError:
+		_ = c.JSON(http.StatusConflict, err)
+
+	case domain.NotFoundError:
+		_ = c.JSON(http.StatusNotFound, err)
+
 	case domain.DataNotFoundError:
 		res := domain.UserError{
 			Code:    domain.ErrorCodeINVALID_REQUEST,
@@ -132,6 +180,13 @@ func errorMiddleware(err error, c echo.Context) {
 		}
 		_ = c.JSON(http.StatusBadRequest, res)
 
+	case domain.TooManyRequestsError:
+		tmrErr := err.(domain.TooManyRequestsError)
+		if tmrErr.RetryAfter > 0 {
+			c.Response().Header().Set("Retry-After", strconv.FormatInt(tmrErr.RetryAfter, 10))
+		}
+		_ = c.JSON(http.StatusTooManyRequests, tmrErr)
+
 	case domain.UnauthorizedError:
 		res := domain.UnauthorizedError{
 			Code:    domain.ErrorCodeUNAUTHORIZED,
@@ -144,12 +199,18 @@ func errorMiddleware(err error, c echo.Context) {
 			Code:    domain.ErrorCodeFORBIDDEN_ACCESS,
 			Message: domain.MessageFORBIDDENACCESS,
 		}
+		// A missing second factor or step-up is reported as such, so clients know what to ask the user for
+		if fbdErr := err.(domain.ForbiddenAccessError); fbdErr.Code == domain.ErrorCodeMFA_REQUIRED || fbdErr.Code == domain.ErrorCodeSTEP_UP_REQUIRED {
+			res = fbdErr
+		}
 		_ = c.JSON(http.StatusForbidden, res)
 
 	default:
+		// Unexpected errors may carry internal details, they are logged and replaced by a generic message
+		slog.Error("unhandled error", "method", c.Request().Method, "path", c.Path(), "err", err)
 		res := domain.SystemError{
 			Code:    domain.ErrorCodeINTERNAL_SERVER_ERROR,
-			Message: err.Error(),
+			Message: domain.MessageSOMETHINGWENTWRONG,
 		}
 		_ = c.JSON(http.StatusInternalServerError, res)
 	}
@@ -1,16 +1,30 @@
 package api
 
 import (
+	"crypto/subtle"
+	"expvar"
+	"time"
+
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	echomiddleware "github.com/labstack/echo/v4/middleware"
 
+	"github.com/weCredit/internal/domain"
 	"github.com/weCredit/internal/http/controller"
 	"github.com/weCredit/internal/pkg/config"
+	"github.com/weCredit/internal/pkg/security"
 )
 
 type WeCreditApi struct {
-	cfg            config.WeCreditConfig
-	UserController controller.UserController
+	cfg                       config.WeCreditConfig
+	rs                        security.RevocationStore
+	scm                       security.Manager
+	us                        domain.UserService
+	BorrowerProfileController controller.BorrowerProfileController
+	MfaController             controller.MfaController
+	NotificationController    controller.NotificationController
+	UserController            controller.UserController
+	WellKnownController       controller.WellKnownController
 }
 
 // NewWeChatApi creates a new WeCredit instance
@@ -28,24 +42,98 @@ type WeCreditApi struct {
 //	@securityDefinitions.apiKey	JWT
 //	@in							header
 //	@name						Authorization
-func NewWeCreditApi(cfg config.WeCreditConfig, uc controller.UserController) *WeCreditApi {
+func NewWeCreditApi(cfg config.WeCreditConfig, rs security.RevocationStore, scm security.Manager, us domain.UserService, bpc controller.BorrowerProfileController, mc controller.MfaController, nc controller.NotificationController, uc controller.UserController, wkc controller.WellKnownController) *WeCreditApi {
 	return &WeCreditApi{
-		cfg:            cfg,
-		UserController: uc,
+		cfg:                       cfg,
+		rs:                        rs,
+		scm:                       scm,
+		us:                        us,
+		BorrowerProfileController: bpc,
+		MfaController:             mc,
+		NotificationController:    nc,
+		UserController:            uc,
+		WellKnownController:       wkc,
 	}
 }
 
 func (b WeCreditApi) SetupRoutes(e *echo.Echo) {
+	e.GET("/.well-known/jwks.json", b.WellKnownController.Jwks)
+	// Metrics are only exposed when credentials are configured
+	if b.cfg.MetricsUsername != "" && b.cfg.MetricsPassword != "" {
+		e.GET("/debug/vars", echo.WrapHandler(expvar.Handler()), echomiddleware.BasicAuth(b.checkMetricsCredentials))
+	}
+
 	apiV1 := e.Group("/api/v1")
 
-	auth := echojwt.JWT([]byte(b.cfg.AuthSecret))
+	auth := echojwt.WithConfig(echojwt.Config{KeyFunc: b.scm.KeyFunc})
+	// Sensitive operations need an authentication, at login or through a step-up, within the last STEP_UP_MAX_AGE seconds
+	recentAuth := requireRecentAuth(time.Duration(b.cfg.StepUpMaxAge) * time.Second)
 
 	userApi := apiV1.Group("/users")
 	userApi.POST("/login", b.UserController.Login)
+	userApi.POST("/login/magic-link", b.UserController.LoginWithMagicLink)
 	userApi.POST("", b.UserController.RegisterUser)
 	userApi.POST("/init/login", b.UserController.InitLogin)
+	userApi.POST("/token/refresh", b.UserController.RefreshToken)
 	secureApi := apiV1.Group("/users")
-	secureApi.Use(auth)
-	secureApi.GET("/:id", b.UserController.FindByID)
+	secureApi.Use(auth, b.checkRevocation)
+	secureApi.POST("/logout", b.UserController.Logout)
+	secureApi.POST("/logout/all", b.UserController.LogoutAll)
+	secureApi.GET("/me", b.UserController.FindMe)
+	secureApi.PATCH("/me", b.UserController.UpdateMe)
+	secureApi.GET("/me/logins", b.UserController.FindMyLogins)
+	secureApi.GET("/me/preferences", b.UserController.FindPreferences)
+	secureApi.PUT("/me/preferences", b.UserController.ReplacePreferences)
+	secureApi.PATCH("/me/preferences", b.UserController.PatchPreferences)
+	secureApi.GET("/me/borrower-profile", b.BorrowerProfileController.FindMine)
+	secureApi.PUT("/me/borrower-profile", b.BorrowerProfileController.SaveMine)
+	secureApi.POST("/step-up", b.UserController.StepUpChallenge)
+	secureApi.POST("/step-up/verify", b.UserController.StepUpVerify)
+	secureApi.POST("/mfa/totp", b.MfaController.EnrollTotp)
+	secureApi.POST("/mfa/totp/verify", b.MfaController.ConfirmTotp)
+	secureApi.DELETE("/mfa/totp", b.MfaController.DisableTotp, recentAuth)
+	secureApi.POST("/mfa/recovery-codes", b.MfaController.RegenerateRecoveryCodes, recentAuth)
+
+	// Every resource below /users/:id belongs to that user
+	userResourceApi := secureApi.Group("/:id", requireOwnership("id"))
+	userResourceApi.GET("", b.UserController.FindByID)
+	userResourceApi.PUT("/email", b.UserController.RequestEmailVerification, recentAuth)
+	userResourceApi.POST("/email/verify", b.UserController.VerifyEmail)
+	userResourceApi.POST("/phone", b.UserController.RequestPhoneChange, recentAuth)
+	userResourceApi.POST("/phone/verify", b.UserController.VerifyPhoneChange)
+	userResourceApi.GET("/devices", b.UserController.FindDevices)
+	userResourceApi.DELETE("/devices/:deviceId", b.UserController.RevokeDevice)
+	userResourceApi.POST("/deactivate", b.UserController.Deactivate, recentAuth)
+
+	// Delivery reports posted by the providers, authenticated by their signature
+	notificationApi := apiV1.Group("/notifications")
+	notificationApi.POST("/twilio/status", b.NotificationController.TwilioStatus, b.verifyTwilioSignature)
+	notificationApi.POST("/webhook/status", b.NotificationController.WebhookStatus, b.verifyWebhookSignature)
+
+	adminApi := apiV1.Group("/admin")
+	adminApi.Use(auth, b.checkRevocation)
+	adminApi.GET("/users", b.UserController.FindUsers, requirePermissions(domain.PermissionUSERS_READ))
+	adminApi.PUT("/users/:id/role", b.UserController.UpdateRole, requirePermissions(domain.PermissionUSERS_MANAGE_ROLES))
+	adminApi.PUT("/users/:id/status", b.UserController.ChangeStatus, requirePermissions(domain.PermissionUSERS_MANAGE_STATUS))
+	adminApi.POST("/users/:id/phone", b.UserController.AdminRequestPhoneChange, requirePermissions(domain.PermissionUSERS_WRITE))
+	adminApi.POST("/users/:id/phone/verify", b.UserController.AdminVerifyPhoneChange, requirePermissions(domain.PermissionUSERS_WRITE))
+	adminApi.GET("/users/:id/borrower-profile", b.BorrowerProfileController.FindByUserID, requirePermissions(domain.PermissionUSERS_READ))
+	adminApi.GET("/users/:id/otp-deliveries", b.UserController.FindOtpDeliveries, requirePermissions(domain.PermissionUSERS_READ))
+	adminApi.GET("/login-events", b.UserController.FindLoginEvents, requirePermissions(domain.PermissionUSERS_READ))
+
+}
+
+// BootstrapAdmin creates the first admin from BOOTSTRAP_ADMIN_USERNAME, it does nothing once an admin exists
+func (b WeCreditApi) BootstrapAdmin() error {
+	if b.cfg.BootstrapAdminUsername == "" {
+		return nil
+	}
+	return b.us.BootstrapAdmin(b.cfg.BootstrapAdminUsername)
+}
 
+// checkMetricsCredentials validates the basic auth credentials of the metrics endpoint
+func (b WeCreditApi) checkMetricsCredentials(username, password string, ctx echo.Context) (bool, error) {
+	validUsername := subtle.ConstantTimeCompare([]byte(username), []byte(b.cfg.MetricsUsername)) == 1
+	validPassword := subtle.ConstantTimeCompare([]byte(password), []byte(b.cfg.MetricsPassword)) == 1
+	return validUsername && validPassword, nil
 }
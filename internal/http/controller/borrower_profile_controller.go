@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/http/transport"
+)
+
+// BorrowerProfileController manages the demographic profile loans are underwritten on. Borrowers only reach
+// their own profile through the token, staff read any profile with the users:read permission.
+type BorrowerProfileController struct {
+	bps domain.BorrowerProfileService
+}
+
+func NewBorrowerProfileController(bps domain.BorrowerProfileService) BorrowerProfileController {
+	return BorrowerProfileController{bps: bps}
+}
+
+// FindMine finds the borrower profile of the current user.
+//
+//	@Summary		Find my borrower profile
+//	@Description	Find the borrower profile of the user of the token
+//	@Tags			Borrower
+//	@ID				findMyBorrowerProfile
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Success		200				{object}	domain.BaseResponse{data=domain.BorrowerProfile}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/borrower-profile [get]
+func (c BorrowerProfileController) FindMine(ctx echo.Context) error {
+	userID, err := userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to find the profile
+	result, err := c.bps.FindByUserID(userID)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// SaveMine creates or replaces the borrower profile of the current user.
+//
+//	@Summary		Save my borrower profile
+//	@Description	Create or replace the borrower profile of the user of the token. Addresses must be in India, with a pincode of their state. Without a permanent address the current address is used for both. The employer is required for salaried borrowers
+//	@Tags			Borrower
+//	@ID				saveMyBorrowerProfile
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string							true	"Bearer "
+//	@Param			body			body		domain.SaveBorrowerProfileInput	true	"Borrower profile"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.BorrowerProfile}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/borrower-profile [put]
+func (c BorrowerProfileController) SaveMine(ctx echo.Context) error {
+	userID, err := userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Decode the request body
+	var in domain.SaveBorrowerProfileInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.UserID = userID
+	// Call the service to save the profile
+	result, err := c.bps.Save(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindByUserID finds the borrower profile of a user.
+//
+//	@Summary		Find borrower profile
+//	@Description	Find the borrower profile of a user. Requires the users:read permission
+//	@Tags			Admin
+//	@ID				findBorrowerProfile
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"User ID"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.BorrowerProfile}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/admin/users/{id}/borrower-profile [get]
+func (c BorrowerProfileController) FindByUserID(ctx echo.Context) error {
+	// Parse the path param
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// Call the service to find the profile
+	result, err := c.bps.FindByUserID(id)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
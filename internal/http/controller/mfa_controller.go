@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/labstack/echo/v4"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/http/transport"
+	"github.com/weCredit/internal/pkg/security"
+)
+
+// MfaController manages the second factor of the current user. The routes take the user from the token,
+// so nobody can enroll or disable a second factor on behalf of someone else.
+type MfaController struct {
+	ms domain.MfaService
+}
+
+func NewMfaController(ms domain.MfaService) MfaController {
+	return MfaController{ms: ms}
+}
+
+// EnrollTotp starts the authenticator app enrollment of the current user.
+//
+//	@Summary		Enroll authenticator app
+//	@Description	Create a TOTP secret for the current user. Render the provisioning URI as a QR code for the authenticator app, then confirm it with a code. Enrolling again before confirming replaces the secret
+//	@Tags			MFA
+//	@ID				enrollTotp
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Success		200				{object}	domain.BaseResponse{data=domain.TotpEnrollmentOutput}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/mfa/totp [post]
+func (c MfaController) EnrollTotp(ctx echo.Context) error {
+	userID, err := userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to enroll the user
+	result, err := c.ms.EnrollTotp(userID)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// ConfirmTotp enables the authenticator app of the current user.
+//
+//	@Summary		Confirm authenticator app
+//	@Description	Check a code of the authenticator app and enable it. The response holds the recovery codes, which are only shown once. Log in again to get a token that carries the second factor
+//	@Tags			MFA
+//	@ID				confirmTotp
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string				true	"Bearer "
+//	@Param			body			body		domain.MfaCodeInput	true	"Code input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.RecoveryCodesOutput}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		429				{object}	domain.TooManyRequestsError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/mfa/totp/verify [post]
+func (c MfaController) ConfirmTotp(ctx echo.Context) error {
+	in, err := decodeMfaCodeInput(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to confirm the enrollment
+	result, err := c.ms.ConfirmTotp(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// DisableTotp disables the authenticator app of the current user.
+//
+//	@Summary		Disable authenticator app
+//	@Description	Disable the authenticator app and delete the recovery codes. Requires a code of the app or a recovery code
+//	@Tags			MFA
+//	@ID				disableTotp
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header	string				true	"Bearer "
+//	@Param			body			body	domain.MfaCodeInput	true	"Code input"
+//	@Success		204
+//	@Failure		400	{object}	domain.InvalidRequestError
+//	@Failure		401	{object}	domain.UnauthorizedError
+//	@Failure		403	{object}	domain.ForbiddenAccessError
+//	@Failure		429	{object}	domain.TooManyRequestsError
+//	@Failure		500	{object}	domain.SystemError
+//	@Router			/users/mfa/totp [delete]
+func (c MfaController) DisableTotp(ctx echo.Context) error {
+	in, err := decodeMfaCodeInput(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to disable the second factor
+	err = c.ms.DisableTotp(in)
+	if err != nil {
+		return err
+	}
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// RegenerateRecoveryCodes replaces the recovery codes of the current user.
+//
+//	@Summary		Regenerate recovery codes
+//	@Description	Replace the recovery codes, the old ones stop working. Requires a code of the app or a recovery code
+//	@Tags			MFA
+//	@ID				regenerateRecoveryCodes
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string				true	"Bearer "
+//	@Param			body			body		domain.MfaCodeInput	true	"Code input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.RecoveryCodesOutput}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		429				{object}	domain.TooManyRequestsError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/mfa/recovery-codes [post]
+func (c MfaController) RegenerateRecoveryCodes(ctx echo.Context) error {
+	in, err := decodeMfaCodeInput(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to replace the recovery codes
+	result, err := c.ms.RegenerateRecoveryCodes(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// decodeMfaCodeInput decodes the request body and sets the user of the token
+func decodeMfaCodeInput(ctx echo.Context) (in domain.MfaCodeInput, err error) {
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return in, err
+	}
+	in.ID, err = userIDForContext(ctx)
+	return in, err
+}
+
+// userIDForContext returns the user of the auth token
+func userIDForContext(ctx echo.Context) (uuid.UUID, error) {
+	claims, ok := security.GetTokenClaimsForContext(ctx)
+	if !ok {
+		return uuid.Nil, domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+	}
+	return uuid.FromString(claims.UserID)
+}
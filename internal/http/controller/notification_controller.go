@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/http/transport"
+	"github.com/weCredit/internal/pkg/notification"
+)
+
+type NotificationController struct {
+	ns domain.NotificationService
+}
+
+func NewNotificationController(ns domain.NotificationService) NotificationController {
+	return NotificationController{ns: ns}
+}
+
+// TwilioStatus receives the delivery reports of twilio.
+//
+//	@Summary		Twilio delivery report
+//	@Description	Status callback of twilio messages. The request must carry a valid X-Twilio-Signature header
+//	@Tags			Notification
+//	@ID				twilioDeliveryStatus
+//	@Accept			x-www-form-urlencoded
+//	@Param			X-Twilio-Signature	header		string	true	"Signature of the request"
+//	@Param			MessageSid			formData	string	true	"Message SID"
+//	@Param			MessageStatus		formData	string	true	"Message status"
+//	@Param			ErrorCode			formData	string	false	"Error code"
+//	@Param			To					formData	string	false	"Recipient"
+//	@Success		204
+//	@Failure		400	{object}	domain.InvalidRequestError
+//	@Failure		403	{object}	domain.ForbiddenAccessError
+//	@Failure		500	{object}	domain.SystemError
+//	@Router			/notifications/twilio/status [post]
+func (c NotificationController) TwilioStatus(ctx echo.Context) error {
+	in := domain.DeliveryStatusInput{
+		Provider:       notification.ProviderTwilio,
+		MessageID:      ctx.FormValue("MessageSid"),
+		ProviderStatus: ctx.FormValue("MessageStatus"),
+		ErrorCode:      ctx.FormValue("ErrorCode"),
+		To:             ctx.FormValue("To"),
+	}
+	if in.MessageID == "" || in.ProviderStatus == "" {
+		return domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: "MessageSid and MessageStatus are required"}
+	}
+	// Call the service to update the delivery status
+	err := c.ns.UpdateDeliveryStatus(in)
+	if err != nil {
+		return err
+	}
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// WebhookStatus receives the delivery reports of the webhook provider.
+//
+//	@Summary		Webhook delivery report
+//	@Description	Status callback of messages sent through the webhook provider. The body must be signed like the messages, in the X-WeCredit-Signature header
+//	@Tags			Notification
+//	@ID				webhookDeliveryStatus
+//	@Accept			json
+//	@Param			X-WeCredit-Signature	header	string								true	"Signature of the request body"
+//	@Param			body					body	domain.WebhookDeliveryStatusInput	true	"Delivery report"
+//	@Success		204
+//	@Failure		400	{object}	domain.InvalidRequestError
+//	@Failure		403	{object}	domain.ForbiddenAccessError
+//	@Failure		500	{object}	domain.SystemError
+//	@Router			/notifications/webhook/status [post]
+func (c NotificationController) WebhookStatus(ctx echo.Context) error {
+	// Decode the request body
+	var body domain.WebhookDeliveryStatusInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &body)
+	if err != nil {
+		return err
+	}
+	in := domain.DeliveryStatusInput{
+		Provider:       notification.ProviderWebhook,
+		MessageID:      body.MessageID,
+		ProviderStatus: body.Status,
+		ErrorCode:      body.ErrorCode,
+		To:             body.To,
+	}
+	// Call the service to update the delivery status
+	err = c.ns.UpdateDeliveryStatus(in)
+	if err != nil {
+		return err
+	}
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
@@ -2,12 +2,15 @@ package controller
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/labstack/echo/v4"
 
 	"github.com/weCredit/internal/domain"
 	"github.com/weCredit/internal/http/transport"
+	"github.com/weCredit/internal/pkg/security"
 )
 
 type UserController struct {
@@ -51,6 +54,160 @@ func (c UserController) FindByID(ctx echo.Context) error {
 	return transport.SendResponse(ctx, http.StatusOK, result)
 }
 
+// FindMe finds the current user.
+//
+//	@Summary		Find current user
+//	@Description	Find the user of the token
+//	@Tags			User
+//	@ID				findMe
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Success		200				{object}	domain.BaseResponse{data=domain.User}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me [get]
+func (c UserController) FindMe(ctx echo.Context) error {
+	userID, err := userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to find the user by id
+	result, err := c.us.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// UpdateMe updates the profile of the current user.
+//
+//	@Summary		Update current user
+//	@Description	Update the profile of the user of the token. Omitted fields are left unchanged. The mobile number, email and role cannot be changed here
+//	@Tags			User
+//	@ID				updateMe
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string					true	"Bearer "
+//	@Param			body			body		domain.UpdateUserInput	true	"Profile fields to update"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.User}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me [patch]
+func (c UserController) UpdateMe(ctx echo.Context) error {
+	userID, err := userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Decode the request body
+	var in domain.UpdateUserInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ID = userID
+	// Call the service to update the profile
+	result, err := c.us.UpdateProfile(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindPreferences finds the preferences of the current user.
+//
+//	@Summary		Find my preferences
+//	@Description	Find the preferences of the user of the token. Preferences never set have their default value
+//	@Tags			User
+//	@ID				findMyPreferences
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Success		200				{object}	domain.BaseResponse{data=domain.UserPreferences}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/preferences [get]
+func (c UserController) FindPreferences(ctx echo.Context) error {
+	userID, err := userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to find the preferences
+	result, err := c.us.FindPreferences(userID)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// ReplacePreferences replaces the preferences of the current user.
+//
+//	@Summary		Replace my preferences
+//	@Description	Replace the preferences of the user of the token. Omitted keys are reset to their default value and unknown keys are rejected
+//	@Tags			User
+//	@ID				replaceMyPreferences
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string					true	"Bearer "
+//	@Param			body			body		domain.UserPreferences	true	"Preferences"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.UserPreferences}
+//	@Failure		400				{object}	domain.ValidationError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/preferences [put]
+func (c UserController) ReplacePreferences(ctx echo.Context) error {
+	return c.updatePreferences(ctx, false)
+}
+
+// PatchPreferences merge-patches the preferences of the current user.
+//
+//	@Summary		Update my preferences
+//	@Description	Apply a JSON merge patch (RFC 7386) to the preferences of the user of the token. Only the given keys change, a null resets the key to its default value
+//	@Tags			User
+//	@ID				patchMyPreferences
+//	@Accept			application/merge-patch+json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string					true	"Bearer "
+//	@Param			body			body		domain.UserPreferences	true	"Preferences to change"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.UserPreferences}
+//	@Failure		400				{object}	domain.ValidationError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/preferences [patch]
+func (c UserController) PatchPreferences(ctx echo.Context) error {
+	return c.updatePreferences(ctx, true)
+}
+
+// updatePreferences replaces or merge-patches the preferences of the current user with the request body
+func (c UserController) updatePreferences(ctx echo.Context, merge bool) error {
+	userID, err := userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// The keys are checked against the preferences schema by the service, so the body is kept as is
+	prefs, err := transport.DecodeJSONObject(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to update the preferences
+	result, err := c.us.UpdatePreferences(domain.UpdatePreferencesInput{ID: userID, Preferences: prefs, Merge: merge})
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
 // Login authenticates a user based on login credentials.
 //
 //	@Summary		User login
@@ -63,6 +220,7 @@ func (c UserController) FindByID(ctx echo.Context) error {
 //	@Success		200		{object}	domain.BaseResponse{data=domain.LoginOutput}
 //	@Failure		400		{object}	domain.InvalidRequestError
 //	@Failure		401		{object}	domain.UnauthorizedError
+//	@Failure		429		{object}	domain.TooManyRequestsError
 //	@Failure		500		{object}	domain.SystemError
 //	@Router			/users/login [post]
 func (c UserController) Login(ctx echo.Context) error {
@@ -72,6 +230,7 @@ func (c UserController) Login(ctx echo.Context) error {
 	if err != nil {
 		return err
 	}
+	in.ClientInfo = transport.GetClientInfo(ctx)
 	// Call the service to login
 	result, err := c.us.Login(in)
 	if err != nil {
@@ -82,6 +241,37 @@ func (c UserController) Login(ctx echo.Context) error {
 
 }
 
+// LoginWithMagicLink exchanges a magic link for a token.
+//
+//	@Summary		Magic link login
+//	@Description	Exchange the token of a magic link sent by Initialize Login with the MAGIC_LINK mode for a JWT token and a refresh token. The binding token returned by Initialize Login must be sent along from the same browser or app. A link can only be used once
+//	@Tags			Auth
+//	@ID				magicLinkLogin
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.MagicLinkLoginInput	true	"Magic link input"
+//	@Success		200		{object}	domain.BaseResponse{data=domain.LoginOutput}
+//	@Failure		400		{object}	domain.InvalidRequestError
+//	@Failure		429		{object}	domain.TooManyRequestsError
+//	@Failure		500		{object}	domain.SystemError
+//	@Router			/users/login/magic-link [post]
+func (c UserController) LoginWithMagicLink(ctx echo.Context) error {
+	// Decode the request body
+	var in domain.MagicLinkLoginInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ClientInfo = transport.GetClientInfo(ctx)
+	// Call the service to login
+	result, err := c.us.LoginWithMagicLink(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
 // RegisterUser  Register a new user
 //
 //	@Summary		Register a new user
@@ -90,16 +280,20 @@ func (c UserController) Login(ctx echo.Context) error {
 //	@Accept			json
 //	@Produce		json
 //	@Param			user	body		domain.RegisterUserInput	true	"User registration details"
-//	@Success		200		{object}	domain.BaseResponse{data=domain.User}"
+//	@Success		201		{object}	domain.BaseResponse{data=domain.User}"
 //	@Failure		400		{object}	domain.InvalidRequestError
 //	@Failure		401		{object}	domain.UnauthorizedError
 //	@Failure		403		{object}	domain.ForbiddenAccessError
+//	@Failure		409		{object}	domain.ConflictError
 //	@Failure		500		{object}	domain.SystemError
 //	@Router			/users [post]
 func (c UserController) RegisterUser(ctx echo.Context) error {
 	// Decode the request body
 	var in domain.RegisterUserInput
-	transport.DecodeAndValidateRequestBody(ctx, &in)
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
 
 	// Call service method to create  a new user
 	result, err := c.us.RegisterUser(in)
@@ -113,25 +307,890 @@ func (c UserController) RegisterUser(ctx echo.Context) error {
 // InitLogin initiates the login process for a user.
 //
 //	@Summary		Initiate login
-//	@Description	Initiates the login process, such as sending an OTP or a link for authentication
+//	@Description	Initiates the login process by sending an OTP by SMS or, when the user has a verified email, by email
 //	@Tags			Auth
 //	@ID				initUserLogin
 //	@Accept			json
 //	@Produce		json
 //	@Param			body	body		domain.InitLoginInput	true	"Login initiation input"
-//	@Success		200		{object}	domain.BaseResponse{data=interface{}}
+//	@Success		200		{object}	domain.BaseResponse{data=domain.InitLoginOutput}
 //	@Failure		400		{object}	domain.InvalidRequestError
+//	@Failure		404		{object}	domain.NotFoundError
+//	@Failure		429		{object}	domain.TooManyRequestsError
 //	@Failure		500		{object}	domain.SystemError
 //	@Router			/users/init/login [post]
 func (c UserController) InitLogin(ctx echo.Context) error {
 	var in domain.InitLoginInput
-	transport.DecodeAndValidateRequestBody(ctx, &in)
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ClientInfo = transport.GetClientInfo(ctx)
 
 	// Call service method to initiate login
-	err := c.us.InitLogin(in)
+	result, err := c.us.InitLogin(in)
+	if err != nil {
+		return err
+	}
+	return transport.SendResponse(ctx, http.StatusOK, result)
+
+}
+
+// RequestEmailVerification sends a verification code to a new email of the user.
+//
+//	@Summary		Request email verification
+//	@Description	Send a verification code to the email. The email is only linked to the user once the code is verified
+//	@Tags			User
+//	@ID				requestEmailVerification
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string					true	"Bearer "
+//	@Param			id				path		string					true	"User ID"
+//	@Param			body			body		domain.UpdateEmailInput	true	"Email input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.InitLoginOutput}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		429				{object}	domain.TooManyRequestsError
+//	@Failure		409				{object}	domain.ConflictError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/{id}/email [put]
+func (c UserController) RequestEmailVerification(ctx echo.Context) error {
+	// Parse the path param
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// Decode the request body
+	var in domain.UpdateEmailInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ID = id
+	// Call the service to send the verification code
+	result, err := c.us.RequestEmailVerification(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// VerifyEmail verifies the email of the user.
+//
+//	@Summary		Verify email
+//	@Description	Check the verification code sent to the email and link the email to the user
+//	@Tags			User
+//	@ID				verifyEmail
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string					true	"Bearer "
+//	@Param			id				path		string					true	"User ID"
+//	@Param			body			body		domain.VerifyEmailInput	true	"Verification input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.User}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		429				{object}	domain.TooManyRequestsError
+//	@Failure		409				{object}	domain.ConflictError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/{id}/email/verify [post]
+func (c UserController) VerifyEmail(ctx echo.Context) error {
+	// Parse the path param
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// Decode the request body
+	var in domain.VerifyEmailInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ID = id
+	// Call the service to verify the email
+	result, err := c.us.VerifyEmail(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// RequestPhoneChange sends the codes to change the mobile number of the user.
+//
+//	@Summary		Request phone number change
+//	@Description	Send a code to the current and to the new mobile number. Requires a recent authentication
+//	@Tags			User
+//	@ID				requestPhoneChange
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string					true	"Bearer "
+//	@Param			id				path		string					true	"User ID"
+//	@Param			body			body		domain.ChangePhoneInput	true	"New mobile number"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.InitLoginOutput}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		429				{object}	domain.TooManyRequestsError
+//	@Failure		409				{object}	domain.ConflictError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/{id}/phone [post]
+func (c UserController) RequestPhoneChange(ctx echo.Context) error {
+	return c.requestPhoneChange(ctx, false)
+}
+
+// VerifyPhoneChange changes the mobile number of the user.
+//
+//	@Summary		Verify phone number change
+//	@Description	Check the codes sent to the current and to the new mobile number, change the number and log the user out everywhere
+//	@Tags			User
+//	@ID				verifyPhoneChange
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string							true	"Bearer "
+//	@Param			id				path		string							true	"User ID"
+//	@Param			body			body		domain.VerifyPhoneChangeInput	true	"Verification input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.User}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		429				{object}	domain.TooManyRequestsError
+//	@Failure		409				{object}	domain.ConflictError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/{id}/phone/verify [post]
+func (c UserController) VerifyPhoneChange(ctx echo.Context) error {
+	return c.verifyPhoneChange(ctx, false)
+}
+
+// AdminRequestPhoneChange sends the code to change the mobile number of a user who lost the current one.
+//
+//	@Summary		Request assisted phone number change
+//	@Description	Send a code to the new mobile number only, for a user who lost the current one. Requires the users:write permission
+//	@Tags			Admin
+//	@ID				adminRequestPhoneChange
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string					true	"Bearer "
+//	@Param			id				path		string					true	"User ID"
+//	@Param			body			body		domain.ChangePhoneInput	true	"New mobile number"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.InitLoginOutput}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		429				{object}	domain.TooManyRequestsError
+//	@Failure		409				{object}	domain.ConflictError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/admin/users/{id}/phone [post]
+func (c UserController) AdminRequestPhoneChange(ctx echo.Context) error {
+	return c.requestPhoneChange(ctx, true)
+}
+
+// AdminVerifyPhoneChange changes the mobile number of a user who lost the current one.
+//
+//	@Summary		Verify assisted phone number change
+//	@Description	Check the code sent to the new mobile number, change the number and log the user out everywhere. Requires the users:write permission
+//	@Tags			Admin
+//	@ID				adminVerifyPhoneChange
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string							true	"Bearer "
+//	@Param			id				path		string							true	"User ID"
+//	@Param			body			body		domain.VerifyPhoneChangeInput	true	"Verification input, old_otp is ignored"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.User}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		429				{object}	domain.TooManyRequestsError
+//	@Failure		409				{object}	domain.ConflictError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/admin/users/{id}/phone/verify [post]
+func (c UserController) AdminVerifyPhoneChange(ctx echo.Context) error {
+	return c.verifyPhoneChange(ctx, true)
+}
+
+// requestPhoneChange decodes the phone change request of the user in the path and sends the codes
+func (c UserController) requestPhoneChange(ctx echo.Context, adminAssisted bool) error {
+	// Parse the path param
+	id, err := uuid.FromString(ctx.Param("id"))
 	if err != nil {
 		return err
 	}
-	return transport.SendResponse(ctx, http.StatusOK, nil)
+	// Decode the request body
+	var in domain.ChangePhoneInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ID = id
+	in.AdminAssisted = adminAssisted
+	in.ActorID, err = userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to send the codes
+	result, err := c.us.RequestPhoneChange(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// verifyPhoneChange decodes the phone change verification of the user in the path and changes the number
+func (c UserController) verifyPhoneChange(ctx echo.Context, adminAssisted bool) error {
+	// Parse the path param
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// Decode the request body
+	var in domain.VerifyPhoneChangeInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ID = id
+	in.AdminAssisted = adminAssisted
+	in.ActorID, err = userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	in.ClientInfo = transport.GetClientInfo(ctx)
+	// Call the service to change the number
+	result, err := c.us.VerifyPhoneChange(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
 
+// RefreshToken exchanges a refresh token for a new token pair.
+//
+//	@Summary		Refresh token
+//	@Description	Exchange a refresh token for a new access token. The refresh token is rotated on every use and reusing an old one revokes all sessions of its family
+//	@Tags			Auth
+//	@ID				refreshToken
+//	@Accept			json
+//	@Produce		json
+//	@Param			body	body		domain.RefreshTokenInput	true	"Refresh token input"
+//	@Success		200		{object}	domain.BaseResponse{data=domain.LoginOutput}
+//	@Failure		400		{object}	domain.InvalidRequestError
+//	@Failure		401		{object}	domain.UnauthorizedError
+//	@Failure		500		{object}	domain.SystemError
+//	@Router			/users/token/refresh [post]
+func (c UserController) RefreshToken(ctx echo.Context) error {
+	// Decode the request body
+	var in domain.RefreshTokenInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ClientInfo = transport.GetClientInfo(ctx)
+	// Call the service to refresh the token
+	result, err := c.us.RefreshToken(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// Logout logs out the current session.
+//
+//	@Summary		Logout
+//	@Description	Revoke the access token of the request and the refresh tokens of its session
+//	@Tags			Auth
+//	@ID				userLogout
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header	string	true	"Bearer "
+//	@Success		204
+//	@Failure		401	{object}	domain.UnauthorizedError
+//	@Failure		500	{object}	domain.SystemError
+//	@Router			/users/logout [post]
+func (c UserController) Logout(ctx echo.Context) error {
+	// Read the claims of the current token
+	claims, ok := security.GetTokenClaimsForContext(ctx)
+	if !ok {
+		return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+	}
+	userID, err := uuid.FromString(claims.UserID)
+	if err != nil {
+		return err
+	}
+	in := domain.LogoutInput{
+		UserID:    userID,
+		TokenID:   claims.TokenID,
+		ExpiresAt: claims.ExpiresAt,
+	}
+	if claims.SessionID != "" {
+		in.SessionID, err = uuid.FromString(claims.SessionID)
+		if err != nil {
+			return err
+		}
+	}
+	// Call the service to logout
+	err = c.us.Logout(in)
+	if err != nil {
+		return err
+	}
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// LogoutAll logs out all devices of the current user.
+//
+//	@Summary		Logout all devices
+//	@Description	Revoke every access token and refresh token of the current user
+//	@Tags			Auth
+//	@ID				userLogoutAll
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header	string	true	"Bearer "
+//	@Success		204
+//	@Failure		401	{object}	domain.UnauthorizedError
+//	@Failure		500	{object}	domain.SystemError
+//	@Router			/users/logout/all [post]
+func (c UserController) LogoutAll(ctx echo.Context) error {
+	// Read the claims of the current token
+	claims, ok := security.GetTokenClaimsForContext(ctx)
+	if !ok {
+		return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+	}
+	userID, err := uuid.FromString(claims.UserID)
+	if err != nil {
+		return err
+	}
+	// Call the service to logout all devices
+	err = c.us.LogoutAll(userID)
+	if err != nil {
+		return err
+	}
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// StepUpChallenge sends a step-up code to the current user.
+//
+//	@Summary		Request step-up
+//	@Description	Send an otp to re-authenticate within the current session, before an operation that requires a recent authentication
+//	@Tags			Auth
+//	@ID				userStepUpChallenge
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string						true	"Bearer "
+//	@Param			body			body		domain.StepUpChallengeInput	false	"Step-up input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.InitLoginOutput}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		429				{object}	domain.TooManyRequestsError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/step-up [post]
+func (c UserController) StepUpChallenge(ctx echo.Context) error {
+	userID, err := userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Decode the request body, which may be empty since the channel is optional
+	var in domain.StepUpChallengeInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ID = userID
+	// Call the service to send the step-up code
+	result, err := c.us.StepUpChallenge(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// StepUpVerify checks the step-up code of the current user.
+//
+//	@Summary		Verify step-up
+//	@Description	Check the step-up otp, and the totp or recovery code once an authenticator app is enrolled, and return an access token with a fresh auth_time for the current session
+//	@Tags			Auth
+//	@ID				userStepUpVerify
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string						true	"Bearer "
+//	@Param			body			body		domain.StepUpVerifyInput	true	"Step-up otp"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.StepUpOutput}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		429				{object}	domain.TooManyRequestsError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/step-up/verify [post]
+func (c UserController) StepUpVerify(ctx echo.Context) error {
+	// Read the claims of the current token
+	claims, ok := security.GetTokenClaimsForContext(ctx)
+	if !ok {
+		return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+	}
+	// Decode the request body
+	var in domain.StepUpVerifyInput
+	err := transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ID, err = uuid.FromString(claims.UserID)
+	if err != nil {
+		return err
+	}
+	// The new token belongs to the same session and keeps its second factor
+	if claims.SessionID != "" {
+		in.SessionID, err = uuid.FromString(claims.SessionID)
+		if err != nil {
+			return err
+		}
+	}
+	in.Mfa = claims.Mfa
+	// Call the service to verify the step-up code
+	result, err := c.us.StepUpVerify(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// UpdateRole changes the role of a user.
+//
+//	@Summary		Update user role
+//	@Description	Change the role of a user. Requires the users:manage_roles permission
+//	@Tags			Admin
+//	@ID				updateUserRole
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string						true	"Bearer "
+//	@Param			id				path		string						true	"User ID"
+//	@Param			body			body		domain.UpdateUserRoleInput	true	"Role input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.User}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/admin/users/{id}/role [put]
+func (c UserController) UpdateRole(ctx echo.Context) error {
+	// Parse the path param
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// Decode the request body
+	var in domain.UpdateUserRoleInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	claims, ok := security.GetTokenClaimsForContext(ctx)
+	if !ok {
+		return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+	}
+	in.ID = id
+	in.ActorID, err = uuid.FromString(claims.UserID)
+	if err != nil {
+		return err
+	}
+	// Call the service to update the role
+	result, err := c.us.UpdateRole(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// ChangeStatus suspends, reactivates, deletes or restores a user.
+//
+//	@Summary		Change user status
+//	@Description	Move a user to ACTIVE, SUSPENDED, DEACTIVATED or DELETED. Requires the users:manage_status permission
+//	@Tags			Admin
+//	@ID				changeUserStatus
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string							true	"Bearer "
+//	@Param			id				path		string							true	"User ID"
+//	@Param			body			body		domain.ChangeUserStatusInput	true	"Status input"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.User}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		409				{object}	domain.ConflictError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/admin/users/{id}/status [put]
+func (c UserController) ChangeStatus(ctx echo.Context) error {
+	// Parse the path param
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// Decode the request body
+	var in domain.ChangeUserStatusInput
+	err = transport.DecodeAndValidateRequestBody(ctx, &in)
+	if err != nil {
+		return err
+	}
+	in.ID = id
+	in.ActorID, err = userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to change the status
+	result, err := c.us.ChangeStatus(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// Deactivate deactivates the account of the user.
+//
+//	@Summary		Deactivate account
+//	@Description	Deactivate the account and log it out everywhere. Only support can reactivate it. Requires a recent authentication
+//	@Tags			User
+//	@ID				deactivateUser
+//	@Accept			json
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"User ID"
+//	@Success		200				{object}	domain.BaseResponse{data=domain.User}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/{id}/deactivate [post]
+func (c UserController) Deactivate(ctx echo.Context) error {
+	// Parse the path param
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	in := domain.ChangeUserStatusInput{
+		ID:     id,
+		Status: domain.UserStatusDEACTIVATED,
+	}
+	in.ActorID, err = userIDForContext(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to deactivate the account
+	result, err := c.us.ChangeStatus(in)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindUsers returns the users matching the filters.
+//
+//	@Summary		Find users
+//	@Description	List the users matching the filters. Deleted users are only listed with status=DELETED. Requires the users:read permission
+//	@Tags			Admin
+//	@ID				findUsers
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			role			query		string	false	"Role"		Enums(ADMIN, LOAN_OFFICER, SUPPORT, USER)
+//	@Param			status			query		string	false	"Status"	Enums(ACTIVE, SUSPENDED, DEACTIVATED, DELETED)
+//	@Param			search			query		string	false	"Part of the name, mobile number or email"
+//	@Param			from			query		string	false	"Created from, RFC 3339"
+//	@Param			to				query		string	false	"Created before, RFC 3339"
+//	@Param			sort			query		string	false	"Sort field"	Enums(created_at, updated_at, full_name, user_name)
+//	@Param			order			query		string	false	"Sort order"	Enums(asc, desc)
+//	@Param			page			query		int		false	"Page, starting at 1, at most 10000"
+//	@Param			size			query		int		false	"Page size"
+//	@Success		200				{object}	domain.PaginationResponse{data=[]domain.User}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/admin/users [get]
+func (c UserController) FindUsers(ctx echo.Context) error {
+	// Parse the query params
+	filter, err := userFilter(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to find the users
+	result, total, err := c.us.FindAll(filter)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendPaginatedResponse(ctx, http.StatusOK, result, total, filter.Page, filter.Size)
+}
+
+// userFilter parses the user filters of the query params. Users are sorted by creation time, newest first, by default.
+func userFilter(ctx echo.Context) (filter domain.UserFilter, err error) {
+	filter.Page, filter.Size, err = transport.GetPagination(ctx)
+	if err != nil {
+		return filter, err
+	}
+	var role, status, sortBy, order string
+	err = echo.QueryParamsBinder(ctx).
+		String("role", &role).
+		String("status", &status).
+		String("search", &filter.Search).
+		Time("from", &filter.From, time.RFC3339).
+		Time("to", &filter.To, time.RFC3339).
+		String("sort", &sortBy).
+		String("order", &order).
+		BindError()
+	if err != nil {
+		return filter, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: "from and to must be RFC 3339 times"}
+	}
+	filter.Role = domain.UserRole(role)
+	if role != "" && !filter.Role.IsValid() {
+		return filter, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: "role must be ADMIN, LOAN_OFFICER, SUPPORT or USER"}
+	}
+	filter.Status = domain.UserStatus(status)
+	if status != "" && !filter.Status.IsValid() {
+		return filter, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: "status must be ACTIVE, SUSPENDED, DEACTIVATED or DELETED"}
+	}
+	filter.SortBy = domain.UserSortFieldCREATED_AT
+	if sortBy != "" {
+		filter.SortBy = domain.UserSortField(sortBy)
+		if !filter.SortBy.IsValid() {
+			return filter, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: "sort must be created_at, updated_at, full_name or user_name"}
+		}
+	}
+	switch order {
+	case "":
+		filter.SortDesc = sortBy == ""
+	case "asc":
+		filter.SortDesc = false
+	case "desc":
+		filter.SortDesc = true
+	default:
+		return filter, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: "order must be asc or desc"}
+	}
+	filter.Search = strings.TrimSpace(filter.Search)
+	return filter, nil
+}
+
+// FindOtpDeliveries returns the delivery state of the recent codes of a user.
+//
+//	@Summary		Find OTP deliveries
+//	@Description	Show whether the last 50 codes of a user, used ones included, went out, through which provider and with which message ID. Requires the users:read permission
+//	@Tags			Admin
+//	@ID				findOtpDeliveries
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"User ID"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.OtpDelivery}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/admin/users/{id}/otp-deliveries [get]
+func (c UserController) FindOtpDeliveries(ctx echo.Context) error {
+	// Parse the path param
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// Call the service to find the deliveries
+	result, err := c.us.FindOtpDeliveries(id)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// FindDevices returns the devices a user logged in from.
+//
+//	@Summary		Find devices
+//	@Description	List the devices the user logged in from, most recently seen first, including revoked ones
+//	@Tags			User
+//	@ID				findUserDevices
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			id				path		string	true	"User ID"
+//	@Success		200				{object}	domain.BaseResponse{data=[]domain.UserDevice}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/{id}/devices [get]
+func (c UserController) FindDevices(ctx echo.Context) error {
+	// Parse the path param
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	// Call the service to find the devices
+	result, err := c.us.FindDevices(id)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendResponse(ctx, http.StatusOK, result)
+}
+
+// RevokeDevice revokes a device of a user.
+//
+//	@Summary		Revoke device
+//	@Description	Revoke a device and the refresh tokens of the sessions started on it. The next login from the device sends a new device alert again
+//	@Tags			User
+//	@ID				revokeUserDevice
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header	string	true	"Bearer "
+//	@Param			id				path	string	true	"User ID"
+//	@Param			deviceId		path	string	true	"Device ID"
+//	@Success		204
+//	@Failure		400	{object}	domain.InvalidRequestError
+//	@Failure		401	{object}	domain.UnauthorizedError
+//	@Failure		403	{object}	domain.ForbiddenAccessError
+//	@Failure		500	{object}	domain.SystemError
+//	@Router			/users/{id}/devices/{deviceId} [delete]
+func (c UserController) RevokeDevice(ctx echo.Context) error {
+	// Parse the path params
+	id, err := uuid.FromString(ctx.Param("id"))
+	if err != nil {
+		return err
+	}
+	deviceID, err := uuid.FromString(ctx.Param("deviceId"))
+	if err != nil {
+		return err
+	}
+	// Call the service to revoke the device
+	err = c.us.RevokeDevice(domain.RevokeDeviceInput{ID: id, DeviceID: deviceID})
+	if err != nil {
+		return err
+	}
+	return transport.SendResponse(ctx, http.StatusNoContent, nil)
+}
+
+// FindMyLogins returns the login history of the current user.
+//
+//	@Summary		Find my logins
+//	@Description	List the authentication attempts on the account of the current user, most recent first
+//	@Tags			User
+//	@ID				findMyLogins
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			outcome			query		string	false	"Outcome"	Enums(OTP_SENT, LINK_SENT, SUCCESS, WRONG_OTP, EXPIRED, INVALID_LINK, LOCKED, INACTIVE, MFA_REQUIRED, WRONG_MFA_CODE, RATE_LIMITED, FAILED)
+//	@Param			from			query		string	false	"Start time, RFC 3339"
+//	@Param			to				query		string	false	"End time, RFC 3339"
+//	@Param			page			query		int		false	"Page, starting at 1, at most 10000"
+//	@Param			size			query		int		false	"Page size"
+//	@Success		200				{object}	domain.PaginationResponse{data=[]domain.LoginEvent}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/users/me/logins [get]
+func (c UserController) FindMyLogins(ctx echo.Context) error {
+	// Parse the query params
+	filter, err := loginEventFilter(ctx)
+	if err != nil {
+		return err
+	}
+	claims, ok := security.GetTokenClaimsForContext(ctx)
+	if !ok {
+		return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+	}
+	userID, err := uuid.FromString(claims.UserID)
+	if err != nil {
+		return err
+	}
+	// Only the events of the current user
+	filter.UserID = &userID
+	filter.Username = ""
+	filter.IPAddress = ""
+	// Call the service to find the events
+	result, total, err := c.us.FindLoginEvents(filter)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendPaginatedResponse(ctx, http.StatusOK, result, total, filter.Page, filter.Size)
+}
+
+// FindLoginEvents returns the login events matching the filters.
+//
+//	@Summary		Find login events
+//	@Description	List the authentication attempts of every user, most recent first. Attempts for unknown usernames have no user ID. Requires the users:read permission
+//	@Tags			Admin
+//	@ID				findLoginEvents
+//	@Produce		json
+//	@Security		JWT
+//	@Param			Authorization	header		string	true	"Bearer "
+//	@Param			user_id			query		string	false	"User ID"
+//	@Param			username		query		string	false	"Username"
+//	@Param			outcome			query		string	false	"Outcome"	Enums(OTP_SENT, LINK_SENT, SUCCESS, WRONG_OTP, EXPIRED, INVALID_LINK, LOCKED, INACTIVE, MFA_REQUIRED, WRONG_MFA_CODE, RATE_LIMITED, FAILED)
+//	@Param			ip_address		query		string	false	"IP address"
+//	@Param			from			query		string	false	"Start time, RFC 3339"
+//	@Param			to				query		string	false	"End time, RFC 3339"
+//	@Param			page			query		int		false	"Page, starting at 1, at most 10000"
+//	@Param			size			query		int		false	"Page size"
+//	@Success		200				{object}	domain.PaginationResponse{data=[]domain.LoginEvent}
+//	@Failure		400				{object}	domain.InvalidRequestError
+//	@Failure		401				{object}	domain.UnauthorizedError
+//	@Failure		403				{object}	domain.ForbiddenAccessError
+//	@Failure		500				{object}	domain.SystemError
+//	@Router			/admin/login-events [get]
+func (c UserController) FindLoginEvents(ctx echo.Context) error {
+	// Parse the query params
+	filter, err := loginEventFilter(ctx)
+	if err != nil {
+		return err
+	}
+	// Call the service to find the events
+	result, total, err := c.us.FindLoginEvents(filter)
+	if err != nil {
+		return err
+	}
+	// Return the result
+	return transport.SendPaginatedResponse(ctx, http.StatusOK, result, total, filter.Page, filter.Size)
+}
+
+// loginEventFilter parses the login event filters of the query params
+func loginEventFilter(ctx echo.Context) (filter domain.LoginEventFilter, err error) {
+	filter.Page, filter.Size, err = transport.GetPagination(ctx)
+	if err != nil {
+		return filter, err
+	}
+	var userID, outcome string
+	err = echo.QueryParamsBinder(ctx).
+		String("user_id", &userID).
+		String("username", &filter.Username).
+		String("outcome", &outcome).
+		String("ip_address", &filter.IPAddress).
+		Time("from", &filter.From, time.RFC3339).
+		Time("to", &filter.To, time.RFC3339).
+		BindError()
+	if err != nil {
+		return filter, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: "from and to must be RFC 3339 times"}
+	}
+	if userID != "" {
+		id, err := uuid.FromString(userID)
+		if err != nil {
+			return filter, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: "user_id must be a UUID"}
+		}
+		filter.UserID = &id
+	}
+	filter.Outcome = domain.LoginEventOutcome(outcome)
+	return filter, nil
 }
@@ -0,0 +1,26 @@
+package controller
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+
+	"github.com/weCredit/internal/pkg/security"
+)
+
+type WellKnownController struct {
+	scm security.Manager
+}
+
+func NewWellKnownController(scm security.Manager) WellKnownController {
+	return WellKnownController{scm: scm}
+}
+
+// Jwks publishes the public keys that weCredit tokens can be verified with.
+//
+// The key set follows RFC 7517 and is served as is, without the base response envelope,
+// so that standard JWT libraries can consume it. It is empty while tokens are signed with HS256.
+func (c WellKnownController) Jwks(ctx echo.Context) error {
+	ctx.Response().Header().Set("Cache-Control", "public, max-age=300")
+	return ctx.JSON(http.StatusOK, c.scm.JWKS())
+}
@@ -1,6 +1,7 @@
 package transport
 
 import (
+	"encoding/json"
 	"net/http"
 
 	"github.com/go-playground/validator"
@@ -10,7 +11,12 @@ import (
 	"github.com/weCredit/internal/domain"
 )
 
-const PageMax = 500
+const (
+	// PageMax defines the largest page size
+	PageMax = 500
+	// PageNumberMax defines the last page that can be requested, it keeps the offset far from overflowing
+	PageNumberMax = 10000
+)
 
 // DecodeAndValidateRequestBody decodes and validates the request body
 func DecodeAndValidateRequestBody(ctx echo.Context, t interface{}) error {
@@ -29,6 +35,16 @@ func DecodeAndValidateRequestBody(ctx echo.Context, t interface{}) error {
 	return nil
 }
 
+// DecodeJSONObject decodes a request body that must be a JSON object, whatever its JSON media type,
+// for bodies like merge patches that are not bound to a struct
+func DecodeJSONObject(ctx echo.Context) (result domain.JSONB, err error) {
+	err = json.NewDecoder(ctx.Request().Body).Decode(&result)
+	if err != nil || result == nil {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessageJSONOBJECTREQUIRED}
+	}
+	return result, nil
+}
+
 // SendResponse sends a response
 func SendResponse(ctx echo.Context, status int, data interface{}) error {
 	var finalResult domain.BaseResponse
@@ -43,6 +59,48 @@ func SendResponse(ctx echo.Context, status int, data interface{}) error {
 	return ctx.JSON(status, finalResult)
 }
 
+// SendPaginatedResponse sends a page of results with the total number of results
+func SendPaginatedResponse(ctx echo.Context, status int, data interface{}, total, page, size int64) error {
+	return ctx.JSON(status, domain.PaginationResponse{
+		Data:  data,
+		Total: total,
+		Page:  page,
+		Size:  size,
+	})
+}
+
+// GetPagination returns the page and size query params. Pages start at 1 and end at PageNumberMax, the size
+// defaults to 10 and is capped at PageMax.
+func GetPagination(ctx echo.Context) (page, size int64, err error) {
+	page, size = 1, 10
+	err = echo.QueryParamsBinder(ctx).Int64("page", &page).Int64("size", &size).BindError()
+	if err != nil {
+		return 0, 0, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessageINVALIDPAGINATION}
+	}
+	if page < 1 {
+		page = 1
+	}
+	if page > PageNumberMax {
+		return 0, 0, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessagePAGETOOLARGE}
+	}
+	if size < 1 {
+		size = 10
+	}
+	if size > PageMax {
+		size = PageMax
+	}
+	return page, size, nil
+}
+
+// GetClientInfo returns the details of the client that sent the request
+func GetClientInfo(ctx echo.Context) domain.ClientInfo {
+	return domain.ClientInfo{
+		IPAddress: ctx.RealIP(),
+		UserAgent: ctx.Request().UserAgent(),
+		RequestID: ctx.Response().Header().Get(echo.HeaderXRequestID),
+	}
+}
+
 // CustomValidator custom validator for echo
 type CustomValidator struct {
 	Validator *validator.Validate
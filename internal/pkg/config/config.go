@@ -2,15 +2,23 @@ package config
 
 import (
 	"fmt"
+	"regexp"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
+// e164Pattern matches a mobile number in E.164 format, the format of the usernames
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{1,14}$`)
+
 const (
 	SourceKey = "CONFIG_SOURCE"
 	SourceEnv = "ENVIRONMENT"
 )
 
+// RateLimitRetention defines how long the postgres rate limit store keeps hits, no window may exceed it
+const RateLimitRetention = 24 * time.Hour
+
 type WeCreditConfig struct {
 	DatabaseHost     string `mapstructure:"DB_HOST"`
 	DatabasePort     string `mapstructure:"DB_PORT"`
@@ -22,14 +30,69 @@ type WeCreditConfig struct {
 	AuthSecret       string `mapstructure:"AUTH_SECRET"`
 	AuthExpiryPeriod int    `mapstructure:"AUTH_EXPIRY_PERIOD"`
 
+	AuthSigningAlgorithm     string `mapstructure:"AUTH_SIGNING_ALGORITHM"`
+	AuthSigningKeyID         string `mapstructure:"AUTH_SIGNING_KEY_ID"`
+	AuthSigningKeyFile       string `mapstructure:"AUTH_SIGNING_KEY_FILE"`
+	AuthVerificationKeyFiles string `mapstructure:"AUTH_VERIFICATION_KEY_FILES"`
+
+	RefreshTokenExpiryPeriod int `mapstructure:"REFRESH_TOKEN_EXPIRY_PERIOD"`
+	RevocationCacheTTL       int `mapstructure:"REVOCATION_CACHE_TTL"`
+
 	SwaggerHostUrl    string `mapstructure:"SWAGGER_HOST_URL"`
 	SwaggerHostScheme string `mapstructure:"SWAGGER_HOST_SCHEME"`
 	SwaggerUsername   string `mapstructure:"SWAGGER_USERNAME"`
 	SwaggerPassword   string `mapstructure:"SWAGGER_PASSWORD"`
 
-	AccountSSID      string `mapstructure:"ACCOUNT_SSID"`
-	AccountAuthToken string `mapstructure:"ACCOUNT_AUTH_TOKEN"`
-	TwilioNumber     string `mapstructure:"TWILIO_NUMBER"`
+	AccountSSID             string `mapstructure:"ACCOUNT_SSID"`
+	AccountAuthToken        string `mapstructure:"ACCOUNT_AUTH_TOKEN"`
+	TwilioNumber            string `mapstructure:"TWILIO_NUMBER"`
+	TwilioStatusCallbackUrl string `mapstructure:"TWILIO_STATUS_CALLBACK_URL"`
+
+	MetricsUsername string `mapstructure:"METRICS_USERNAME"`
+	MetricsPassword string `mapstructure:"METRICS_PASSWORD"`
+
+	NotificationProvider      string `mapstructure:"NOTIFICATION_PROVIDER"`
+	NotificationSpoolDir      string `mapstructure:"NOTIFICATION_SPOOL_DIR"`
+	NotificationWebhookUrl    string `mapstructure:"NOTIFICATION_WEBHOOK_URL"`
+	NotificationWebhookSecret string `mapstructure:"NOTIFICATION_WEBHOOK_SECRET"`
+	NotificationMaxRetries    int    `mapstructure:"NOTIFICATION_MAX_RETRIES"`
+	NotificationRetryBackoff  int    `mapstructure:"NOTIFICATION_RETRY_BACKOFF"`
+	NotificationConsoleReveal bool   `mapstructure:"NOTIFICATION_CONSOLE_REVEAL"`
+
+	SmtpHost     string `mapstructure:"SMTP_HOST"`
+	SmtpPort     int    `mapstructure:"SMTP_PORT"`
+	SmtpUsername string `mapstructure:"SMTP_USERNAME"`
+	SmtpPassword string `mapstructure:"SMTP_PASSWORD"`
+	SmtpFrom     string `mapstructure:"SMTP_FROM"`
+
+	OtpHashSecret        string `mapstructure:"OTP_HASH_SECRET"`
+	OtpLength            int    `mapstructure:"OTP_LENGTH"`
+	OtpAlphabet          string `mapstructure:"OTP_ALPHABET"`
+	OtpTTL               int    `mapstructure:"OTP_TTL"`
+	OtpResendCooldown    int    `mapstructure:"OTP_RESEND_COOLDOWN"`
+	OtpMaxResendsPerHour int    `mapstructure:"OTP_MAX_RESENDS_PER_HOUR"`
+	OtpMaxAttempts       int    `mapstructure:"OTP_MAX_ATTEMPTS"`
+	OtpLockoutPeriod     int    `mapstructure:"OTP_LOCKOUT_PERIOD"`
+
+	TotpEncryptionKey string `mapstructure:"TOTP_ENCRYPTION_KEY"`
+	TotpIssuer        string `mapstructure:"TOTP_ISSUER"`
+	MfaAttemptWindow  int    `mapstructure:"MFA_ATTEMPT_WINDOW"`
+
+	MagicLinkURL    string `mapstructure:"MAGIC_LINK_URL"`
+	MagicLinkTTL    int    `mapstructure:"MAGIC_LINK_TTL"`
+	MagicLinkSecret string `mapstructure:"MAGIC_LINK_SECRET"`
+
+	StepUpMaxAge int `mapstructure:"STEP_UP_MAX_AGE"`
+
+	BootstrapAdminUsername string `mapstructure:"BOOTSTRAP_ADMIN_USERNAME"`
+
+	RateLimitStore          string `mapstructure:"RATE_LIMIT_STORE"`
+	RateLimitUsername       int    `mapstructure:"RATE_LIMIT_USERNAME"`
+	RateLimitUsernameWindow int    `mapstructure:"RATE_LIMIT_USERNAME_WINDOW"`
+	RateLimitIP             int    `mapstructure:"RATE_LIMIT_IP"`
+	RateLimitIPWindow       int    `mapstructure:"RATE_LIMIT_IP_WINDOW"`
+	RateLimitPrefix         int    `mapstructure:"RATE_LIMIT_PREFIX"`
+	RateLimitPrefixWindow   int    `mapstructure:"RATE_LIMIT_PREFIX_WINDOW"`
 }
 
 type Options struct {
@@ -46,6 +109,7 @@ func NewConfig(opt Options) (WeCreditConfig, error) {
 func NewFromEnvironmentVariable(opt Options) (WeCreditConfig, error) {
 	viper.SetConfigFile(opt.ConfigFile)
 	viper.SetConfigType("env")
+	setDefaults()
 
 	err := viper.ReadInConfig()
 	if err != nil {
@@ -57,6 +121,91 @@ func NewFromEnvironmentVariable(opt Options) (WeCreditConfig, error) {
 	if err != nil {
 		return WeCreditConfig{}, fmt.Errorf("failed to load configuration: %v", err)
 	}
+	err = validate(cfg)
+	if err != nil {
+		return WeCreditConfig{}, fmt.Errorf("invalid configuration: %v", err)
+	}
 
 	return cfg, nil
 }
+
+// setDefaults sets the values used when a key is missing from the configuration.
+func setDefaults() {
+	viper.SetDefault("AUTH_SIGNING_ALGORITHM", "HS256")
+	viper.SetDefault("REFRESH_TOKEN_EXPIRY_PERIOD", 720)
+	viper.SetDefault("REVOCATION_CACHE_TTL", 30)
+	viper.SetDefault("NOTIFICATION_MAX_RETRIES", 2)
+	viper.SetDefault("NOTIFICATION_RETRY_BACKOFF", 500)
+	viper.SetDefault("SMTP_PORT", 587)
+	viper.SetDefault("TOTP_ISSUER", "weCredit")
+	viper.SetDefault("MFA_ATTEMPT_WINDOW", 900)
+	viper.SetDefault("MAGIC_LINK_TTL", 600)
+	viper.SetDefault("STEP_UP_MAX_AGE", 300)
+	viper.SetDefault("OTP_LENGTH", 6)
+	viper.SetDefault("OTP_ALPHABET", "0123456789")
+	viper.SetDefault("OTP_TTL", 300)
+	viper.SetDefault("OTP_RESEND_COOLDOWN", 30)
+	viper.SetDefault("OTP_MAX_RESENDS_PER_HOUR", 5)
+	viper.SetDefault("OTP_MAX_ATTEMPTS", 5)
+	viper.SetDefault("OTP_LOCKOUT_PERIOD", 15)
+	viper.SetDefault("RATE_LIMIT_STORE", "memory")
+	viper.SetDefault("RATE_LIMIT_USERNAME", 5)
+	viper.SetDefault("RATE_LIMIT_USERNAME_WINDOW", 3600)
+	viper.SetDefault("RATE_LIMIT_IP", 20)
+	viper.SetDefault("RATE_LIMIT_IP_WINDOW", 3600)
+	viper.SetDefault("RATE_LIMIT_PREFIX", 500)
+	viper.SetDefault("RATE_LIMIT_PREFIX_WINDOW", 3600)
+}
+
+// validate checks the values that would make the application misbehave at runtime.
+func validate(cfg WeCreditConfig) error {
+	if cfg.OtpLength < 4 || cfg.OtpLength > 12 {
+		return fmt.Errorf("OTP_LENGTH must be between 4 and 12, got %d", cfg.OtpLength)
+	}
+	if len(cfg.OtpAlphabet) < 2 || len(cfg.OtpAlphabet) > 256 {
+		return fmt.Errorf("OTP_ALPHABET must contain between 2 and 256 characters")
+	}
+	if cfg.OtpTTL <= 0 {
+		return fmt.Errorf("OTP_TTL must be positive, got %d", cfg.OtpTTL)
+	}
+	if cfg.OtpMaxAttempts < 1 {
+		return fmt.Errorf("OTP_MAX_ATTEMPTS must be at least 1, got %d", cfg.OtpMaxAttempts)
+	}
+	if cfg.OtpLockoutPeriod < 1 {
+		return fmt.Errorf("OTP_LOCKOUT_PERIOD must be at least 1 minute, got %d", cfg.OtpLockoutPeriod)
+	}
+	if cfg.NotificationMaxRetries < 0 || cfg.NotificationRetryBackoff < 0 {
+		return fmt.Errorf("NOTIFICATION_MAX_RETRIES and NOTIFICATION_RETRY_BACKOFF must not be negative")
+	}
+	if cfg.OtpResendCooldown < 0 {
+		return fmt.Errorf("OTP_RESEND_COOLDOWN must not be negative, got %d", cfg.OtpResendCooldown)
+	}
+	if cfg.StepUpMaxAge <= 0 {
+		return fmt.Errorf("STEP_UP_MAX_AGE must be positive, got %d", cfg.StepUpMaxAge)
+	}
+	if cfg.BootstrapAdminUsername != "" && !e164Pattern.MatchString(cfg.BootstrapAdminUsername) {
+		return fmt.Errorf("BOOTSTRAP_ADMIN_USERNAME must be a mobile number in E.164 format, got %q", cfg.BootstrapAdminUsername)
+	}
+	windows := []struct {
+		key   string
+		value int
+	}{
+		{key: "RATE_LIMIT_USERNAME_WINDOW", value: cfg.RateLimitUsernameWindow},
+		{key: "RATE_LIMIT_IP_WINDOW", value: cfg.RateLimitIPWindow},
+		{key: "RATE_LIMIT_PREFIX_WINDOW", value: cfg.RateLimitPrefixWindow},
+		{key: "MFA_ATTEMPT_WINDOW", value: cfg.MfaAttemptWindow},
+	}
+	for _, w := range windows {
+		// Older hits are purged from the postgres store, a longer window would under-count
+		if w.value < 1 || time.Duration(w.value)*time.Second > RateLimitRetention {
+			return fmt.Errorf("%s must be between 1 and %d seconds, got %d", w.key, int(RateLimitRetention.Seconds()), w.value)
+		}
+	}
+	if cfg.MagicLinkURL != "" && cfg.MagicLinkTTL <= 0 {
+		return fmt.Errorf("MAGIC_LINK_TTL must be positive, got %d", cfg.MagicLinkTTL)
+	}
+	if cfg.MagicLinkURL != "" && (cfg.MagicLinkSecret == "" || cfg.MagicLinkSecret == cfg.OtpHashSecret) {
+		return fmt.Errorf("MAGIC_LINK_SECRET is required with MAGIC_LINK_URL and must differ from OTP_HASH_SECRET")
+	}
+	return nil
+}
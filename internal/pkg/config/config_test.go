@@ -0,0 +1,44 @@
+package config
+
+import "testing"
+
+// validConfig returns a configuration that passes validate
+func validConfig() WeCreditConfig {
+	return WeCreditConfig{
+		OtpLength:               6,
+		OtpAlphabet:             "0123456789",
+		OtpTTL:                  300,
+		OtpMaxAttempts:          5,
+		OtpLockoutPeriod:        15,
+		StepUpMaxAge:            300,
+		MfaAttemptWindow:        900,
+		RateLimitUsernameWindow: 3600,
+		RateLimitIPWindow:       3600,
+		RateLimitPrefixWindow:   3600,
+	}
+}
+
+func TestValidateRejectsLimitsThatFailOpen(t *testing.T) {
+	if err := validate(validConfig()); err != nil {
+		t.Fatalf("valid configuration: got %v, want no error", err)
+	}
+	tests := []struct {
+		name   string
+		modify func(cfg *WeCreditConfig)
+	}{
+		{name: "OTP_MAX_ATTEMPTS of 0", modify: func(cfg *WeCreditConfig) { cfg.OtpMaxAttempts = 0 }},
+		{name: "OTP_LOCKOUT_PERIOD of 0", modify: func(cfg *WeCreditConfig) { cfg.OtpLockoutPeriod = 0 }},
+		{name: "MFA_ATTEMPT_WINDOW of 0", modify: func(cfg *WeCreditConfig) { cfg.MfaAttemptWindow = 0 }},
+		{name: "RATE_LIMIT_IP_WINDOW of 0", modify: func(cfg *WeCreditConfig) { cfg.RateLimitIPWindow = 0 }},
+		{name: "RATE_LIMIT_PREFIX_WINDOW beyond the retention", modify: func(cfg *WeCreditConfig) { cfg.RateLimitPrefixWindow = 86401 }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.modify(&cfg)
+			if err := validate(cfg); err == nil {
+				t.Error("got no error, want the configuration to be rejected")
+			}
+		})
+	}
+}
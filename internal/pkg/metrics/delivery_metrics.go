@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"expvar"
+	"sync"
+
+	"github.com/weCredit/internal/domain"
+)
+
+// DeliveryRecorder defines the methods that any recorder of otp delivery reports should implement
+type DeliveryRecorder interface {
+	// RecordDeliveryStatus counts a final delivery status for the carrier prefix of the recipient
+	RecordDeliveryStatus(prefix string, status domain.DeliveryStatus)
+}
+
+// deliveryCounts holds the final delivery statuses of a carrier prefix
+type deliveryCounts struct {
+	Delivered       int64   `json:"delivered"`
+	Undelivered     int64   `json:"undelivered"`
+	UndeliveredRate float64 `json:"undelivered_rate"`
+}
+
+// expvarDeliveryRecorder publishes the counts per carrier prefix as the otp_delivery expvar
+type expvarDeliveryRecorder struct {
+	mu     sync.Mutex
+	counts map[string]*deliveryCounts
+}
+
+var (
+	deliveryRecorder     *expvarDeliveryRecorder
+	deliveryRecorderOnce sync.Once
+)
+
+// NewDeliveryRecorder returns the recorder published as the otp_delivery expvar.
+//
+// Expvars are process wide, so every call returns the same recorder.
+func NewDeliveryRecorder() DeliveryRecorder {
+	deliveryRecorderOnce.Do(func() {
+		deliveryRecorder = &expvarDeliveryRecorder{counts: map[string]*deliveryCounts{}}
+		expvar.Publish("otp_delivery", expvar.Func(deliveryRecorder.snapshot))
+	})
+	return deliveryRecorder
+}
+
+func (r *expvarDeliveryRecorder) RecordDeliveryStatus(prefix string, status domain.DeliveryStatus) {
+	if prefix == "" {
+		prefix = "unknown"
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c, ok := r.counts[prefix]
+	if !ok {
+		c = &deliveryCounts{}
+		r.counts[prefix] = c
+	}
+	switch status {
+	case domain.DeliveryStatusDELIVERED:
+		c.Delivered++
+	case domain.DeliveryStatusUNDELIVERED:
+		c.Undelivered++
+	default:
+		return
+	}
+	c.UndeliveredRate = float64(c.Undelivered) / float64(c.Delivered+c.Undelivered)
+}
+
+// snapshot copies the counts so they can be encoded without holding the lock
+func (r *expvarDeliveryRecorder) snapshot() any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	result := make(map[string]deliveryCounts, len(r.counts))
+	for prefix, c := range r.counts {
+		result[prefix] = *c
+	}
+	return result
+}
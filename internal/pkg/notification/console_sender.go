@@ -0,0 +1,41 @@
+package notification
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strings"
+
+	"github.com/weCredit/internal/domain"
+)
+
+// linkTokenPattern matches the token of a magic link, without the full stop that may end the sentence
+var linkTokenPattern = regexp.MustCompile(`token=[^&\s]*[^&\s.]`)
+
+// consoleSender writes the message to the application log, meant for local development only.
+// Codes and link tokens are masked unless reveal is set, so they never end up in shared logs by accident.
+type consoleSender struct {
+	reveal bool
+}
+
+// NewConsoleSender creates a new console sender
+func NewConsoleSender(reveal bool) Sender {
+	return &consoleSender{reveal: reveal}
+}
+
+func (s *consoleSender) Send(ctx context.Context, msg domain.OtpMessage) (receipt domain.DeliveryReceipt, err error) {
+	body := messageBody(msg)
+	if !s.reveal {
+		body = maskSecrets(body, msg.Otp)
+	}
+	slog.Info("notification", "channel", channelOrDefault(msg.Channel), "to", msg.To, "body", body)
+	return receipt, nil
+}
+
+// maskSecrets hides the otp and the magic link token of a message body
+func maskSecrets(body, otp string) string {
+	if otp != "" {
+		body = strings.ReplaceAll(body, otp, "******")
+	}
+	return linkTokenPattern.ReplaceAllString(body, "token=******")
+}
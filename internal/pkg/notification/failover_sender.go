@@ -0,0 +1,92 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/weCredit/internal/domain"
+)
+
+// namedSender pairs a provider with its name
+type namedSender struct {
+	name   string
+	sender Sender
+}
+
+// retryPolicy defines how often a provider is retried and the delay before the first retry
+type retryPolicy struct {
+	maxRetries int
+	backoff    time.Duration
+}
+
+// delay returns the wait before the given retry, doubling on every retry
+func (p retryPolicy) delay(retry int) time.Duration {
+	return p.backoff << (retry - 1)
+}
+
+// failoverSender tries every provider in order, retrying each with backoff, until one accepts the message
+type failoverSender struct {
+	providers []namedSender
+	retry     retryPolicy
+}
+
+func newFailoverSender(providers []namedSender, retry retryPolicy) Sender {
+	return &failoverSender{providers: providers, retry: retry}
+}
+
+func (s *failoverSender) Send(ctx context.Context, msg domain.OtpMessage) (receipt domain.DeliveryReceipt, err error) {
+	receipt.Channel = channelOrDefault(msg.Channel)
+	var errs []error
+	for _, p := range s.providers {
+		var lastErr error
+		for attempt := 1; attempt <= s.retry.maxRetries+1; attempt++ {
+			if attempt > 1 {
+				select {
+				case <-ctx.Done():
+					return s.failed(receipt, errors.Join(append(errs, fmt.Errorf("%s: %w", p.name, ctx.Err()))...))
+				case <-time.After(s.retry.delay(attempt - 1)):
+				}
+			}
+			result, sendErr := p.sender.Send(ctx, msg)
+			receipt.Attempts = append(receipt.Attempts, domain.DeliveryAttempt{
+				Provider:  p.name,
+				Attempt:   attempt,
+				MessageID: result.MessageID,
+				Error:     errorString(sendErr),
+				At:        time.Now(),
+			})
+			if sendErr == nil {
+				receipt.Provider = p.name
+				receipt.MessageID = result.MessageID
+				receipt.ProviderStatus = result.ProviderStatus
+				receipt.Status = domain.DeliveryStatusSENT
+				receipt.UpdatedAt = time.Now()
+				return receipt, nil
+			}
+			slog.Warn("notification provider failed", "provider", p.name, "attempt", attempt, "err", sendErr)
+			lastErr = sendErr
+		}
+		// Only the last error of a provider is reported, every attempt is in the receipt
+		errs = append(errs, fmt.Errorf("%s: %w", p.name, lastErr))
+	}
+	return s.failed(receipt, errors.Join(errs...))
+}
+
+// failed completes the receipt of a message that no provider accepted
+func (s *failoverSender) failed(receipt domain.DeliveryReceipt, err error) (domain.DeliveryReceipt, error) {
+	receipt.Status = domain.DeliveryStatusFAILED
+	receipt.Error = errorString(err)
+	receipt.UpdatedAt = time.Now()
+	return receipt, fmt.Errorf("no provider delivered the OTP: %w", err)
+}
+
+// errorString returns the message of the error, or an empty string for nil
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,83 @@
+package notification
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+)
+
+// spooledMessage defines the content of a spooled message file
+type spooledMessage struct {
+	Channel   domain.NotificationChannel `json:"channel"`
+	To        string                     `json:"to"`
+	Otp       string                     `json:"otp"`
+	Body      string                     `json:"body"`
+	CreatedAt time.Time                  `json:"created_at"`
+}
+
+// fileSender writes every message as a JSON file into a spool directory so tests can read the otp back
+type fileSender struct {
+	dir string
+}
+
+// NewFileSender creates a new file spool sender
+func NewFileSender(cfg config.WeCreditConfig) (Sender, error) {
+	if cfg.NotificationSpoolDir == "" {
+		return nil, errors.New("file provider requires NOTIFICATION_SPOOL_DIR")
+	}
+	if err := os.MkdirAll(cfg.NotificationSpoolDir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create spool directory: %w", err)
+	}
+	return &fileSender{dir: cfg.NotificationSpoolDir}, nil
+}
+
+func (s *fileSender) Send(ctx context.Context, msg domain.OtpMessage) (receipt domain.DeliveryReceipt, err error) {
+	now := time.Now()
+	data, err := json.Marshal(spooledMessage{
+		Channel:   channelOrDefault(msg.Channel),
+		To:        msg.To,
+		Otp:       msg.Otp,
+		Body:      messageBody(msg),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return receipt, err
+	}
+
+	// Write to a temporary file first so readers never see a partial message
+	name := fmt.Sprintf("%d_%s.json", now.UnixNano(), spoolName(msg.To))
+	tmp, err := os.CreateTemp(s.dir, ".spool-*")
+	if err != nil {
+		return receipt, err
+	}
+	if _, err = tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return receipt, err
+	}
+	if err = tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return receipt, err
+	}
+	// The file name identifies the message in the spool directory
+	receipt.MessageID = name
+	return receipt, os.Rename(tmp.Name(), filepath.Join(s.dir, name))
+}
+
+// spoolName turns the recipient into a safe file name part
+func spoolName(to string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '.' || r == '@' || r == '-' || r == '_' {
+			return r
+		}
+		return -1
+	}, to)
+}
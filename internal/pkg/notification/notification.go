@@ -0,0 +1,154 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+)
+
+const (
+	ProviderTwilio  = "twilio"
+	ProviderConsole = "console"
+	ProviderFile    = "file"
+	ProviderWebhook = "webhook"
+	ProviderSmtp    = "smtp"
+)
+
+// Sender defines the methods that any OTP delivery provider should implement
+type Sender interface {
+	// Send delivers the otp to the recipient and returns the receipt of the provider
+	Send(ctx context.Context, msg domain.OtpMessage) (receipt domain.DeliveryReceipt, err error)
+}
+
+// NewSender creates the Sender that routes every message to the provider chain of its channel.
+//
+// SMS go through the providers listed in NOTIFICATION_PROVIDER, in order. Emails go to the SMTP server when SMTP_HOST
+// is set, otherwise to the console, file or webhook providers of the list, which handle both channels.
+func NewSender(cfg config.WeCreditConfig) (Sender, error) {
+	names := providerNames(cfg.NotificationProvider)
+	var (
+		sms   []namedSender
+		email []namedSender
+	)
+	for _, name := range names {
+		s, err := newProvider(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		sms = append(sms, namedSender{name: name, sender: s})
+		if name != ProviderTwilio && cfg.SmtpHost == "" {
+			email = append(email, namedSender{name: name, sender: s})
+		}
+	}
+	if cfg.SmtpHost != "" {
+		s, err := NewSmtpSender(cfg)
+		if err != nil {
+			return nil, err
+		}
+		email = append(email, namedSender{name: ProviderSmtp, sender: s})
+	}
+
+	retry := retryPolicy{
+		maxRetries: cfg.NotificationMaxRetries,
+		backoff:    time.Duration(cfg.NotificationRetryBackoff) * time.Millisecond,
+	}
+	result := &channelSender{sms: newFailoverSender(sms, retry)}
+	if len(email) > 0 {
+		result.email = newFailoverSender(email, retry)
+	}
+	return result, nil
+}
+
+// providerNames parses the comma separated provider list, twilio being the default
+func providerNames(value string) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return []string{ProviderTwilio}
+	}
+	return names
+}
+
+// newProvider creates the sender of a single provider
+func newProvider(cfg config.WeCreditConfig, name string) (Sender, error) {
+	switch name {
+	case ProviderTwilio:
+		return NewTwilioSender(cfg)
+	case ProviderConsole:
+		return NewConsoleSender(cfg.NotificationConsoleReveal), nil
+	case ProviderFile:
+		return NewFileSender(cfg)
+	case ProviderWebhook:
+		return NewWebhookSender(cfg)
+	default:
+		return nil, fmt.Errorf("unknown notification provider '%s'", name)
+	}
+}
+
+// channelSender routes a message to the sender of its channel
+type channelSender struct {
+	sms   Sender
+	email Sender
+}
+
+func (s *channelSender) Send(ctx context.Context, msg domain.OtpMessage) (receipt domain.DeliveryReceipt, err error) {
+	switch msg.Channel {
+	case "", domain.NotificationChannelSMS:
+		return s.sms.Send(ctx, msg)
+	case domain.NotificationChannelEMAIL:
+		if s.email == nil {
+			return receipt, domain.UserError{Code: domain.ErrorCodeCHANNEL_UNAVAILABLE, Message: domain.MessageCHANNELUNAVAILABLE}
+		}
+		return s.email.Send(ctx, msg)
+	default:
+		return receipt, fmt.Errorf("unknown notification channel '%s'", msg.Channel)
+	}
+}
+
+// DeliveryStatusOf maps the status reported by a provider to a delivery status.
+//
+// It follows the twilio message statuses, which most SMS gateways share. Statuses that do not end the delivery,
+// such as queued or sending, map to SENT.
+func DeliveryStatusOf(providerStatus string) domain.DeliveryStatus {
+	switch strings.ToLower(strings.TrimSpace(providerStatus)) {
+	case "delivered", "read":
+		return domain.DeliveryStatusDELIVERED
+	case "undelivered", "failed", "canceled":
+		return domain.DeliveryStatusUNDELIVERED
+	default:
+		return domain.DeliveryStatusSENT
+	}
+}
+
+// otpMessageBody renders the text sent to the user for an otp
+func otpMessageBody(otp string) string {
+	return fmt.Sprintf("Your OTP is: %s. Please use this to complete your login. Do not share this code with anyone.", otp)
+}
+
+// messageBody returns the text sent to the user, the otp text unless the message has its own body
+func messageBody(msg domain.OtpMessage) string {
+	if msg.Body != "" {
+		return msg.Body
+	}
+	return otpMessageBody(msg.Otp)
+}
+
+// channelOrDefault returns the channel of a message, which is SMS when not set
+func channelOrDefault(channel domain.NotificationChannel) domain.NotificationChannel {
+	if channel == "" {
+		return domain.NotificationChannelSMS
+	}
+	return channel
+}
@@ -0,0 +1,43 @@
+package notification
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// WebhookSignature returns the hex encoded HMAC-SHA256 of the body, sent and expected in the X-WeCredit-Signature header
+func WebhookSignature(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// TwilioSignature returns the signature twilio sends in the X-Twilio-Signature header of a callback.
+//
+// It is the base64 encoded HMAC-SHA1 of the callback url followed by every POST parameter name and value,
+// sorted by name, keyed with the auth token of the account.
+func TwilioSignature(authToken, callbackUrl string, params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	b.WriteString(callbackUrl)
+	for _, k := range keys {
+		for _, v := range params[k] {
+			b.WriteString(k)
+			b.WriteString(v)
+		}
+	}
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(b.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
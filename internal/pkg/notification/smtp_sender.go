@@ -0,0 +1,140 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+)
+
+const otpEmailSubject = "Your weCredit login code"
+
+// smtpSender sends the otp as an email through an SMTP server
+type smtpSender struct {
+	host     string
+	addr     string
+	username string
+	password string
+	from     mail.Address
+}
+
+// NewSmtpSender creates a new SMTP sender
+func NewSmtpSender(cfg config.WeCreditConfig) (Sender, error) {
+	if cfg.SmtpHost == "" || cfg.SmtpFrom == "" {
+		return nil, errors.New("email channel requires SMTP_HOST and SMTP_FROM")
+	}
+	from, err := mail.ParseAddress(cfg.SmtpFrom)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP_FROM: %w", err)
+	}
+	return &smtpSender{
+		host:     cfg.SmtpHost,
+		addr:     net.JoinHostPort(cfg.SmtpHost, strconv.Itoa(cfg.SmtpPort)),
+		username: cfg.SmtpUsername,
+		password: cfg.SmtpPassword,
+		from:     *from,
+	}, nil
+}
+
+func (s *smtpSender) Send(ctx context.Context, msg domain.OtpMessage) (receipt domain.DeliveryReceipt, err error) {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return receipt, fmt.Errorf("failed to send OTP: invalid recipient: %w", err)
+	}
+	id, err := uuid.NewV4()
+	if err != nil {
+		return receipt, err
+	}
+	messageID := fmt.Sprintf("<%s@%s>", id, s.fromDomain())
+
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return receipt, fmt.Errorf("failed to send OTP: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(30 * time.Second)
+	}
+	conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, s.host)
+	if err != nil {
+		conn.Close()
+		return receipt, fmt.Errorf("failed to send OTP: %w", err)
+	}
+	defer client.Close()
+
+	// Upgrade the connection when the server supports it, local stand-ins usually do not
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err = client.StartTLS(&tls.Config{ServerName: s.host}); err != nil {
+			return receipt, fmt.Errorf("failed to send OTP: %w", err)
+		}
+	}
+	if s.username != "" {
+		// PlainAuth refuses to send the password over an unencrypted connection to a remote host
+		if err = client.Auth(smtp.PlainAuth("", s.username, s.password, s.host)); err != nil {
+			return receipt, fmt.Errorf("failed to send OTP: %w", err)
+		}
+	}
+	if err = client.Mail(s.from.Address); err != nil {
+		return receipt, fmt.Errorf("failed to send OTP: %w", err)
+	}
+	if err = client.Rcpt(to.Address); err != nil {
+		return receipt, fmt.Errorf("failed to send OTP: %w", err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return receipt, fmt.Errorf("failed to send OTP: %w", err)
+	}
+	if _, err = w.Write(s.message(*to, messageID, msg)); err != nil {
+		w.Close()
+		return receipt, fmt.Errorf("failed to send OTP: %w", err)
+	}
+	if err = w.Close(); err != nil {
+		return receipt, fmt.Errorf("failed to send OTP: %w", err)
+	}
+	receipt.MessageID = messageID
+	return receipt, client.Quit()
+}
+
+// fromDomain returns the domain of the sender address, used to build unique message ids
+func (s *smtpSender) fromDomain() string {
+	if i := strings.LastIndex(s.from.Address, "@"); i >= 0 {
+		return s.from.Address[i+1:]
+	}
+	return s.host
+}
+
+// message renders the email including its headers
+func (s *smtpSender) message(to mail.Address, messageID string, msg domain.OtpMessage) []byte {
+	subject := otpEmailSubject
+	if msg.Subject != "" {
+		subject = msg.Subject
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Message-ID: %s\r\n", messageID)
+	fmt.Fprintf(&buf, "From: %s\r\n", s.from.String())
+	fmt.Fprintf(&buf, "To: %s\r\n", to.String())
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(messageBody(msg))
+	buf.WriteString("\r\n")
+	return buf.Bytes()
+}
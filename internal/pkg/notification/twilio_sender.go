@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/twilio/twilio-go"
+	openapi "github.com/twilio/twilio-go/rest/api/v2010"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+)
+
+// twilioSender sends the otp as an SMS using twilio
+type twilioSender struct {
+	client         *twilio.RestClient
+	from           string
+	statusCallback string
+}
+
+// NewTwilioSender creates a new twilio sender
+func NewTwilioSender(cfg config.WeCreditConfig) (Sender, error) {
+	if cfg.AccountSSID == "" || cfg.AccountAuthToken == "" || cfg.TwilioNumber == "" {
+		return nil, errors.New("twilio provider requires ACCOUNT_SSID, ACCOUNT_AUTH_TOKEN and TWILIO_NUMBER")
+	}
+	client := twilio.NewRestClientWithParams(twilio.ClientParams{
+		Username: cfg.AccountSSID,
+		Password: cfg.AccountAuthToken,
+	})
+	return &twilioSender{
+		client:         client,
+		from:           cfg.TwilioNumber,
+		statusCallback: cfg.TwilioStatusCallbackUrl,
+	}, nil
+}
+
+func (s *twilioSender) Send(ctx context.Context, msg domain.OtpMessage) (receipt domain.DeliveryReceipt, err error) {
+	// Prepare the message parameters
+	params := &openapi.CreateMessageParams{}
+	params.SetTo(msg.To)
+	params.SetFrom(s.from)
+	params.SetBody(messageBody(msg))
+	// Ask twilio to post the delivery reports of the message
+	if s.statusCallback != "" {
+		params.SetStatusCallback(s.statusCallback)
+	}
+
+	// Send the message
+	resp, err := s.client.Api.CreateMessage(params)
+	if err != nil {
+		return receipt, fmt.Errorf("failed to send OTP: %w", err)
+	}
+
+	// Keep the SID so the message can be looked up in the twilio console
+	if resp.Sid != nil {
+		receipt.MessageID = *resp.Sid
+	}
+	if resp.Status != nil {
+		receipt.ProviderStatus = *resp.Status
+	}
+	return receipt, nil
+}
@@ -0,0 +1,82 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+)
+
+const (
+	SignatureHeader = "X-WeCredit-Signature"
+	messageIDHeader = "X-Message-Id"
+)
+
+// webhookPayload defines the body posted to the webhook
+type webhookPayload struct {
+	Channel domain.NotificationChannel `json:"channel"`
+	To      string                     `json:"to"`
+	Otp     string                     `json:"otp"`
+	Body    string                     `json:"body"`
+}
+
+// webhookSender posts the otp to a generic HTTP endpoint
+type webhookSender struct {
+	client *http.Client
+	url    string
+	secret string
+}
+
+// NewWebhookSender creates a new webhook sender
+func NewWebhookSender(cfg config.WeCreditConfig) (Sender, error) {
+	if cfg.NotificationWebhookUrl == "" {
+		return nil, errors.New("webhook provider requires NOTIFICATION_WEBHOOK_URL")
+	}
+	return &webhookSender{
+		client: &http.Client{Timeout: 10 * time.Second},
+		url:    cfg.NotificationWebhookUrl,
+		secret: cfg.NotificationWebhookSecret,
+	}, nil
+}
+
+func (s *webhookSender) Send(ctx context.Context, msg domain.OtpMessage) (receipt domain.DeliveryReceipt, err error) {
+	data, err := json.Marshal(webhookPayload{
+		Channel: channelOrDefault(msg.Channel),
+		To:      msg.To,
+		Otp:     msg.Otp,
+		Body:    messageBody(msg),
+	})
+	if err != nil {
+		return receipt, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return receipt, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Sign the payload so the receiver can verify it came from us
+	if s.secret != "" {
+		req.Header.Set(SignatureHeader, WebhookSignature(s.secret, data))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return receipt, fmt.Errorf("failed to send OTP: %w", err)
+	}
+	defer resp.Body.Close()
+	receipt.ProviderStatus = strconv.Itoa(resp.StatusCode)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return receipt, fmt.Errorf("failed to send OTP: webhook responded with status %d", resp.StatusCode)
+	}
+	// The receiver may identify the message with a header, as most SMS gateways do
+	receipt.MessageID = resp.Header.Get(messageIDHeader)
+	return receipt, nil
+}
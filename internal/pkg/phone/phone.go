@@ -0,0 +1,65 @@
+package phone
+
+import "strings"
+
+// twoDigitCountryCodes lists the ITU-T E.164 country calling codes that are two digits long.
+// Codes starting with 1 or 7 are one digit long and every other code is three digits long.
+var twoDigitCountryCodes = map[string]bool{
+	"20": true, "27": true, "30": true, "31": true, "32": true, "33": true, "34": true, "36": true,
+	"39": true, "40": true, "41": true, "43": true, "44": true, "45": true, "46": true, "47": true,
+	"48": true, "49": true, "51": true, "52": true, "53": true, "54": true, "55": true, "56": true,
+	"57": true, "58": true, "60": true, "61": true, "62": true, "63": true, "64": true, "65": true,
+	"66": true, "81": true, "82": true, "84": true, "86": true, "90": true, "91": true, "92": true,
+	"93": true, "94": true, "95": true, "98": true,
+}
+
+// CountryCode returns the country calling code of an E.164 number including the leading +, e.g. "+91".
+//
+// It returns an empty string when the number is not in E.164 format.
+func CountryCode(number string) string {
+	digits, ok := e164Digits(number)
+	if !ok {
+		return ""
+	}
+	switch {
+	case digits[0] == '1' || digits[0] == '7':
+		return "+" + digits[:1]
+	case twoDigitCountryCodes[digits[:2]]:
+		return "+" + digits[:2]
+	default:
+		return "+" + digits[:3]
+	}
+}
+
+// carrierPrefixDigits is the number of national digits that, after the country code, identify the carrier of a number
+const carrierPrefixDigits = 3
+
+// CarrierPrefix returns the country calling code and the first national digits of an E.164 number, e.g. "+91-987".
+//
+// The national digits identify the carrier in most numbering plans while keeping the number of prefixes bounded.
+// It returns an empty string when the number is not in E.164 format.
+func CarrierPrefix(number string) string {
+	cc := CountryCode(number)
+	if cc == "" {
+		return ""
+	}
+	national := strings.TrimPrefix(strings.TrimSpace(number), cc)
+	if len(national) > carrierPrefixDigits {
+		national = national[:carrierPrefixDigits]
+	}
+	return cc + "-" + national
+}
+
+// e164Digits returns the digits of an E.164 number without the leading +
+func e164Digits(number string) (string, bool) {
+	digits, found := strings.CutPrefix(strings.TrimSpace(number), "+")
+	if !found || len(digits) < 4 || len(digits) > 15 {
+		return "", false
+	}
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return "", false
+		}
+	}
+	return digits, true
+}
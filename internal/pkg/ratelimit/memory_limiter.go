@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval defines how often keys without recent hits are dropped
+const sweepInterval = 10 * time.Minute
+
+// memoryLimiter keeps a log of hits per key in memory, limits are per instance
+type memoryLimiter struct {
+	mu        sync.Mutex
+	hits      map[string][]time.Time
+	windows   map[string]time.Duration
+	lastSwept time.Time
+}
+
+// NewMemoryLimiter creates a new in-memory limiter
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{
+		hits:      make(map[string][]time.Time),
+		windows:   make(map[string]time.Duration),
+		lastSwept: time.Now(),
+	}
+}
+
+func (l *memoryLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error) {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.sweep(now)
+
+	// Drop the hits that slid out of the window
+	hits := l.hits[key]
+	start := 0
+	for start < len(hits) && !hits[start].After(now.Add(-window)) {
+		start++
+	}
+	hits = hits[start:]
+	l.windows[key] = window
+
+	if len(hits) >= limit {
+		l.hits[key] = hits
+		return false, hits[len(hits)-limit].Add(window).Sub(now), nil
+	}
+	l.hits[key] = append(hits, now)
+	return true, 0, nil
+}
+
+// sweep drops the keys whose hits have all left their window, the caller must hold the lock
+func (l *memoryLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSwept) < sweepInterval {
+		return
+	}
+	l.lastSwept = now
+	for key, hits := range l.hits {
+		if len(hits) == 0 || !hits[len(hits)-1].After(now.Add(-l.windows[key])) {
+			delete(l.hits, key)
+			delete(l.windows, key)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+)
+
+const (
+	StoreMemory   = "memory"
+	StorePostgres = "postgres"
+)
+
+// Limiter defines the methods that a sliding-window rate limiter should implement
+type Limiter interface {
+	// Allow records a hit for the key when at most limit-1 hits happened within the window.
+	// When the hit is not allowed, retryAfter tells how long until the next hit would be.
+	Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// NewLimiter creates the Limiter selected by the RATE_LIMIT_STORE configuration
+func NewLimiter(cfg config.WeCreditConfig, rlr domain.RateLimitRepository) (Limiter, error) {
+	switch strings.ToLower(strings.TrimSpace(cfg.RateLimitStore)) {
+	case "", StoreMemory:
+		return NewMemoryLimiter(), nil
+	case StorePostgres:
+		return NewRepositoryLimiter(rlr), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store '%s'", cfg.RateLimitStore)
+	}
+}
@@ -0,0 +1,48 @@
+package ratelimit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+)
+
+// repositoryLimiter keeps the hits in the database, so limits are shared by every instance
+type repositoryLimiter struct {
+	rlr domain.RateLimitRepository
+
+	mu         sync.Mutex
+	lastPurged time.Time
+}
+
+// NewRepositoryLimiter creates a new limiter backed by the rate limit repository
+func NewRepositoryLimiter(rlr domain.RateLimitRepository) Limiter {
+	return &repositoryLimiter{
+		rlr:        rlr,
+		lastPurged: time.Now(),
+	}
+}
+
+func (l *repositoryLimiter) Allow(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error) {
+	l.purge(ctx)
+	return l.rlr.Hit(ctx, key, limit, window)
+}
+
+// purge deletes old hits of every key once in a while
+func (l *repositoryLimiter) purge(ctx context.Context) {
+	now := time.Now()
+	l.mu.Lock()
+	if now.Sub(l.lastPurged) < sweepInterval {
+		l.mu.Unlock()
+		return
+	}
+	l.lastPurged = now
+	l.mu.Unlock()
+
+	if err := l.rlr.DeleteBefore(ctx, now.Add(-config.RateLimitRetention)); err != nil {
+		slog.Error("failed to purge rate limit hits", "err", err)
+	}
+}
@@ -3,6 +3,7 @@ package security
 import (
 	"time"
 
+	"github.com/gofrs/uuid/v5"
 	"github.com/golang-jwt/jwt/v5"
 
 	"github.com/weCredit/internal/pkg/config"
@@ -12,7 +13,8 @@ const issuer = "weCredit-app-server"
 
 // jwtSecurityManager represents the JWT security manager
 type jwtSecurityManager struct {
-	cfg config.WeCreditConfig
+	cfg  config.WeCreditConfig
+	keys keySet
 }
 
 // authClaims represents the claims in the auth token
@@ -22,27 +24,50 @@ type authClaims struct {
 }
 
 // NewJwtSecurityManager creates a new JWT security manager
-func NewJwtSecurityManager(cfg config.WeCreditConfig) Manager {
-	return &jwtSecurityManager{
-		cfg: cfg,
+func NewJwtSecurityManager(cfg config.WeCreditConfig) (Manager, error) {
+	keys, err := loadKeySet(cfg)
+	if err != nil {
+		return nil, err
 	}
+	return &jwtSecurityManager{
+		cfg:  cfg,
+		keys: keys,
+	}, nil
 }
 
 // GenerateAuthToken generates an auth token for a user.
 func (s jwtSecurityManager) GenerateAuthToken(metadata TokenMetadata) (token string, err error) {
+	jti, err := uuid.NewV4()
+	if err != nil {
+		return "", err
+	}
 	claims := &authClaims{
 		TokenMetadata: metadata,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti.String(),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour * time.Duration(s.cfg.AuthExpiryPeriod))),
 			Issuer:    issuer,
 		},
 	}
 
-	t := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token, err = t.SignedString([]byte(s.cfg.AuthSecret))
+	t := jwt.NewWithClaims(s.keys.signingMethod, claims)
+	if s.keys.signingID != "" {
+		t.Header["kid"] = s.keys.signingID
+	}
+	token, err = t.SignedString(s.keys.signingKey)
 	if err != nil {
 		return "", err
 	}
 	return token, nil
 }
+
+// KeyFunc returns the key to verify a token with.
+func (s jwtSecurityManager) KeyFunc(token *jwt.Token) (interface{}, error) {
+	return s.keys.keyFunc(token)
+}
+
+// JWKS returns the public keys tokens can be verified with.
+func (s jwtSecurityManager) JWKS() JSONWebKeySet {
+	return s.keys.jwks()
+}
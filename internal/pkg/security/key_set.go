@@ -0,0 +1,234 @@
+package security
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/weCredit/internal/pkg/config"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// JSONWebKey represents a public verification key in JWK format (RFC 7517)
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JSONWebKeySet represents the set of published verification keys
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// verificationKey represents a key that tokens are verified with
+type verificationKey struct {
+	method jwt.SigningMethod
+	key    interface{}
+}
+
+// keySet holds the key new tokens are signed with and every key that is still accepted for verification
+type keySet struct {
+	signingID     string
+	signingMethod jwt.SigningMethod
+	signingKey    interface{}
+	verification  map[string]verificationKey
+}
+
+// loadKeySet loads the signing and verification keys from the configuration
+func loadKeySet(cfg config.WeCreditConfig) (ks keySet, err error) {
+	ks = keySet{
+		signingID:    cfg.AuthSigningKeyID,
+		verification: make(map[string]verificationKey),
+	}
+
+	switch cfg.AuthSigningAlgorithm {
+	case "", AlgorithmHS256:
+		if cfg.AuthSecret == "" {
+			return ks, errors.New("AUTH_SECRET is required for HS256")
+		}
+		ks.signingMethod = jwt.SigningMethodHS256
+		ks.signingKey = []byte(cfg.AuthSecret)
+		ks.verification[ks.signingID] = verificationKey{method: ks.signingMethod, key: ks.signingKey}
+		return ks, nil
+	case AlgorithmRS256, AlgorithmEdDSA:
+	default:
+		return ks, fmt.Errorf("unsupported signing algorithm '%s'", cfg.AuthSigningAlgorithm)
+	}
+
+	if ks.signingID == "" {
+		return ks, errors.New("AUTH_SIGNING_KEY_ID is required for asymmetric signing")
+	}
+	privateKey, err := readPrivateKey(cfg.AuthSigningKeyFile)
+	if err != nil {
+		return ks, err
+	}
+	method, publicKey, err := methodForPrivateKey(privateKey)
+	if err != nil {
+		return ks, err
+	}
+	if method.Alg() != cfg.AuthSigningAlgorithm {
+		return ks, fmt.Errorf("signing key is a %s key but AUTH_SIGNING_ALGORITHM is %s", method.Alg(), cfg.AuthSigningAlgorithm)
+	}
+	ks.signingMethod = method
+	ks.signingKey = privateKey
+	ks.verification[ks.signingID] = verificationKey{method: method, key: publicKey}
+
+	// Keys that were used to sign tokens before a rotation stay valid for verification
+	for _, entry := range strings.Split(cfg.AuthVerificationKeyFiles, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		kid, path, found := strings.Cut(entry, "=")
+		if !found || kid == "" || path == "" {
+			return ks, fmt.Errorf("invalid verification key '%s', expected kid=path", entry)
+		}
+		if _, exists := ks.verification[kid]; exists {
+			return ks, fmt.Errorf("duplicate key id '%s'", kid)
+		}
+		publicKey, err := readPublicKey(path)
+		if err != nil {
+			return ks, err
+		}
+		method, err := methodForPublicKey(publicKey)
+		if err != nil {
+			return ks, err
+		}
+		ks.verification[kid] = verificationKey{method: method, key: publicKey}
+	}
+	return ks, nil
+}
+
+// keyFunc returns the verification key for the token after checking that its algorithm matches the key
+func (ks keySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	vk, ok := ks.verification[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key id '%s'", kid)
+	}
+	if token.Method.Alg() != vk.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method '%s'", token.Method.Alg())
+	}
+	return vk.key, nil
+}
+
+// jwks returns the public verification keys, symmetric keys are never published
+func (ks keySet) jwks() JSONWebKeySet {
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(ks.verification))}
+	for kid, vk := range ks.verification {
+		switch key := vk.key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JSONWebKey{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: AlgorithmRS256,
+				N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JSONWebKey{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: AlgorithmEdDSA,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(key),
+			})
+		}
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// readPrivateKey reads a PKCS#8 or PKCS#1 private key from a PEM file
+func readPrivateKey(path string) (crypto.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported private key in '%s'", path)
+}
+
+// readPublicKey reads a PKIX or PKCS#1 public key from a PEM file
+func readPublicKey(path string) (crypto.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	if key, err := x509.ParsePKIXPublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	if key, err := x509.ParsePKCS1PublicKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unsupported public key in '%s'", path)
+}
+
+// readPEM reads the first PEM block of a file
+func readPEM(path string) (*pem.Block, error) {
+	if path == "" {
+		return nil, errors.New("key file is not configured")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in '%s'", path)
+	}
+	return block, nil
+}
+
+// methodForPrivateKey returns the signing method and the public key of a private key
+func methodForPrivateKey(key crypto.PrivateKey) (jwt.SigningMethod, crypto.PublicKey, error) {
+	switch k := key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, &k.PublicKey, nil
+	case ed25519.PrivateKey:
+		return jwt.SigningMethodEdDSA, k.Public(), nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// methodForPublicKey returns the signing method a public key verifies
+func methodForPublicKey(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
@@ -0,0 +1,35 @@
+package security
+
+import (
+	"github.com/weCredit/internal/pkg/config"
+)
+
+// MagicLinkSigner defines the methods that a magic link signer should implement
+type MagicLinkSigner interface {
+	// Sign returns the signature of the random id of a magic link
+	Sign(id string) string
+	// Verify reports whether the signature matches the id in constant time
+	Verify(signature, id string) bool
+}
+
+// hmacMagicLinkSigner signs magic links with HMAC-SHA256 using a secret of their own, so a leaked
+// OTP_HASH_SECRET does not allow forging links
+type hmacMagicLinkSigner struct {
+	mac hmacOtpHasher
+}
+
+// NewMagicLinkSigner creates a new HMAC based magic link signer. The secret is checked by the configuration
+// when magic links are enabled.
+func NewMagicLinkSigner(cfg config.WeCreditConfig) MagicLinkSigner {
+	return &hmacMagicLinkSigner{
+		mac: hmacOtpHasher{secret: []byte(cfg.MagicLinkSecret)},
+	}
+}
+
+func (s hmacMagicLinkSigner) Sign(id string) string {
+	return s.mac.Hash(id)
+}
+
+func (s hmacMagicLinkSigner) Verify(signature, id string) bool {
+	return s.mac.Compare(signature, id)
+}
@@ -0,0 +1,49 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+
+	"github.com/weCredit/internal/pkg/config"
+)
+
+// OtpHasher defines the methods that an otp hasher should implement
+type OtpHasher interface {
+	// Hash returns the keyed hash of the otp that is safe to store
+	Hash(otp string) string
+	// Compare reports whether the otp matches the stored hash in constant time
+	Compare(hash, otp string) bool
+}
+
+// hmacOtpHasher hashes otps with HMAC-SHA256 using a server secret
+type hmacOtpHasher struct {
+	secret []byte
+}
+
+// NewOtpHasher creates a new HMAC based otp hasher
+func NewOtpHasher(cfg config.WeCreditConfig) (OtpHasher, error) {
+	if cfg.OtpHashSecret == "" {
+		return nil, errors.New("OTP_HASH_SECRET is required")
+	}
+	return &hmacOtpHasher{
+		secret: []byte(cfg.OtpHashSecret),
+	}, nil
+}
+
+func (h hmacOtpHasher) Hash(otp string) string {
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(otp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (h hmacOtpHasher) Compare(hash, otp string) bool {
+	expected, err := hex.DecodeString(hash)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, h.secret)
+	mac.Write([]byte(otp))
+	return hmac.Equal(expected, mac.Sum(nil))
+}
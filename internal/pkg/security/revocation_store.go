@@ -0,0 +1,197 @@
+package security
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+)
+
+// RevocationStore defines the methods that an access-token revocation store should implement
+type RevocationStore interface {
+	// RevokeToken revokes a single access token until it expires
+	RevokeToken(ctx context.Context, claims TokenClaims) (err error)
+	// RevokeAllForUser rejects every access token of the user issued before the second of the given time and returns
+	// that second. It only stores the revocation, pass the result to CacheValidAfter once the transaction commits.
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) (validAfter time.Time, err error)
+	// CacheValidAfter makes a stored RevokeAllForUser visible to this instance immediately
+	CacheValidAfter(userID uuid.UUID, validAfter time.Time)
+	// IsRevoked reports whether the access token has been revoked
+	IsRevoked(ctx context.Context, claims TokenClaims) (result bool, err error)
+}
+
+// cacheEntry represents a cached lookup
+type cacheEntry struct {
+	value    time.Time
+	cachedAt time.Time
+}
+
+// cachedRevocationStore keeps the revocations in Postgres and caches the lookups in memory.
+//
+// Revocations made by this instance are visible immediately, the ones made by other instances once the cache entry expires.
+type cachedRevocationStore struct {
+	rtr domain.RevokedTokenRepository
+	usr domain.UserRepository
+	ttl time.Duration
+
+	mu          sync.RWMutex
+	revoked     map[string]time.Time
+	notRevoked  map[string]time.Time
+	validAfter  map[uuid.UUID]cacheEntry
+	lastEvicted time.Time
+}
+
+// NewRevocationStore creates a new Postgres backed revocation store with an in-memory cache
+func NewRevocationStore(cfg config.WeCreditConfig, rtr domain.RevokedTokenRepository, usr domain.UserRepository) RevocationStore {
+	return &cachedRevocationStore{
+		rtr:        rtr,
+		usr:        usr,
+		ttl:        time.Duration(cfg.RevocationCacheTTL) * time.Second,
+		revoked:    make(map[string]time.Time),
+		notRevoked: make(map[string]time.Time),
+		validAfter: make(map[uuid.UUID]cacheEntry),
+	}
+}
+
+func (s *cachedRevocationStore) RevokeToken(ctx context.Context, claims TokenClaims) (err error) {
+	userID, err := uuid.FromString(claims.UserID)
+	if err != nil {
+		return err
+	}
+	err = s.rtr.Create(ctx, &domain.RevokedToken{
+		TokenID:   claims.TokenID,
+		UserID:    userID,
+		ExpiresAt: claims.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.revoked[claims.TokenID] = claims.ExpiresAt
+	delete(s.notRevoked, claims.TokenID)
+	s.mu.Unlock()
+
+	// Keep the table small, a token that has expired no longer needs to be tracked
+	return s.rtr.DeleteExpired(ctx)
+}
+
+func (s *cachedRevocationStore) RevokeAllForUser(ctx context.Context, userID uuid.UUID, at time.Time) (validAfter time.Time, err error) {
+	// The iat claim has a precision of a second, so a token issued later in the same second must stay valid
+	validAfter = at.Truncate(time.Second)
+	err = s.usr.UpdateTokensValidAfter(ctx, userID, validAfter)
+	if err != nil {
+		return validAfter, err
+	}
+	return validAfter, nil
+}
+
+func (s *cachedRevocationStore) CacheValidAfter(userID uuid.UUID, validAfter time.Time) {
+	s.mu.Lock()
+	s.validAfter[userID] = cacheEntry{value: validAfter, cachedAt: time.Now()}
+	s.mu.Unlock()
+}
+
+func (s *cachedRevocationStore) IsRevoked(ctx context.Context, claims TokenClaims) (result bool, err error) {
+	// Tokens without an id cannot be revoked individually, so they are not accepted
+	if claims.TokenID == "" {
+		return true, nil
+	}
+	userID, err := uuid.FromString(claims.UserID)
+	if err != nil {
+		return true, nil
+	}
+	validAfter, err := s.tokensValidAfter(ctx, userID)
+	if err != nil {
+		// The user no longer exists, so neither do its tokens
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return true, nil
+		}
+		return false, err
+	}
+	// Only tokens issued in an earlier second are rejected, iat < validAfter
+	if claims.IssuedAt.Before(validAfter) {
+		return true, nil
+	}
+	return s.isTokenRevoked(ctx, claims.TokenID)
+}
+
+// tokensValidAfter returns the time before which every token of the user is rejected
+func (s *cachedRevocationStore) tokensValidAfter(ctx context.Context, userID uuid.UUID) (result time.Time, err error) {
+	now := time.Now()
+	s.mu.RLock()
+	entry, ok := s.validAfter[userID]
+	s.mu.RUnlock()
+	if ok && now.Sub(entry.cachedAt) < s.ttl {
+		return entry.value, nil
+	}
+
+	// Deleted users keep their revocation time, their tokens must fail as revoked rather than not found
+	usr, err := s.usr.FindByIDIncludingDeleted(ctx, userID)
+	if err != nil {
+		return result, err
+	}
+	if usr.TokensValidAfter != nil {
+		result = *usr.TokensValidAfter
+	}
+	s.mu.Lock()
+	s.validAfter[userID] = cacheEntry{value: result, cachedAt: now}
+	s.mu.Unlock()
+	return result, nil
+}
+
+// isTokenRevoked looks the token id up in the cache before asking the database
+func (s *cachedRevocationStore) isTokenRevoked(ctx context.Context, tokenID string) (result bool, err error) {
+	now := time.Now()
+	s.mu.RLock()
+	_, revoked := s.revoked[tokenID]
+	checkedAt, checked := s.notRevoked[tokenID]
+	s.mu.RUnlock()
+	if revoked {
+		return true, nil
+	}
+	if checked && now.Sub(checkedAt) < s.ttl {
+		return false, nil
+	}
+
+	result, err = s.rtr.Exists(ctx, tokenID)
+	if err != nil {
+		return false, err
+	}
+	s.mu.Lock()
+	s.evictExpired(now)
+	if result {
+		s.revoked[tokenID] = now.Add(s.ttl)
+	} else {
+		s.notRevoked[tokenID] = now
+	}
+	s.mu.Unlock()
+	return result, nil
+}
+
+// evictExpired drops the cache entries that are no longer useful, the caller must hold the lock
+func (s *cachedRevocationStore) evictExpired(now time.Time) {
+	if now.Sub(s.lastEvicted) < s.ttl {
+		return
+	}
+	s.lastEvicted = now
+	for id, exp := range s.revoked {
+		if now.After(exp) {
+			delete(s.revoked, id)
+		}
+	}
+	for id, checkedAt := range s.notRevoked {
+		if now.Sub(checkedAt) >= s.ttl {
+			delete(s.notRevoked, id)
+		}
+	}
+	for id, entry := range s.validAfter {
+		if now.Sub(entry.cachedAt) >= s.ttl {
+			delete(s.validAfter, id)
+		}
+	}
+}
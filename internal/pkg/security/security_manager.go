@@ -1,20 +1,39 @@
 package security
 
 import (
+	"time"
+
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/labstack/echo/v4"
 )
 
 // TokenMetadata represents the metadata in the auth token
 type TokenMetadata struct {
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
+	UserID    string `json:"user_id"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid,omitempty"`
+	// Mfa is true when the user completed a second factor to get the token
+	Mfa bool `json:"mfa,omitempty"`
+	// AuthTime is when the user last proved their identity, in unix seconds. It is kept on refresh.
+	AuthTime int64 `json:"auth_time,omitempty"`
+}
+
+// TokenClaims represents the verified claims of the auth token of a request
+type TokenClaims struct {
+	TokenMetadata
+	TokenID   string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
 }
 
 // Manager defines the methods that a security manager should implement
 type Manager interface {
 	// GenerateAuthToken generates an auth token for a user.
 	GenerateAuthToken(metadata TokenMetadata) (token string, err error)
+	// KeyFunc returns the key to verify a token with, it rejects unknown key ids and mismatching algorithms.
+	KeyFunc(token *jwt.Token) (interface{}, error)
+	// JWKS returns the public keys tokens can be verified with.
+	JWKS() JSONWebKeySet
 }
 
 func GetClaimsForContext(ctx echo.Context) jwt.MapClaims {
@@ -26,3 +45,26 @@ func GetClaimsForContext(ctx echo.Context) jwt.MapClaims {
 	}
 	return nil
 }
+
+// GetTokenClaimsForContext returns the typed claims of the auth token, ok is false when the request is not authenticated
+func GetTokenClaimsForContext(ctx echo.Context) (result TokenClaims, ok bool) {
+	claims := GetClaimsForContext(ctx)
+	if claims == nil {
+		return result, false
+	}
+	result.UserID, _ = claims["user_id"].(string)
+	result.Role, _ = claims["role"].(string)
+	result.SessionID, _ = claims["sid"].(string)
+	result.TokenID, _ = claims["jti"].(string)
+	result.Mfa, _ = claims["mfa"].(bool)
+	if authTime, ok := claims["auth_time"].(float64); ok {
+		result.AuthTime = int64(authTime)
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		result.IssuedAt = iat.Time
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		result.ExpiresAt = exp.Time
+	}
+	return result, result.UserID != ""
+}
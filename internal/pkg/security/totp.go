@@ -0,0 +1,146 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/weCredit/internal/pkg/config"
+)
+
+const (
+	// totpDigits, totpPeriod and the SHA1 algorithm are the RFC 6238 defaults every authenticator app supports
+	totpDigits = 6
+	totpPeriod = 30
+	// totpSkew is the number of time steps accepted before and after the current one to allow for clock drift
+	totpSkew = 1
+	// totpSecretSize is the size of the shared secret in bytes, as recommended by RFC 4226
+	totpSecretSize = 20
+)
+
+// TotpManager defines the methods that a totp manager should implement
+type TotpManager interface {
+	// GenerateSecret returns a new random base32 encoded secret
+	GenerateSecret() (secret string, err error)
+	// ProvisioningURI returns the otpauth URI authenticator apps read from a QR code
+	ProvisioningURI(account, secret string) string
+	// Validate checks the code against the secret at the given time and returns the time step it matched
+	Validate(secret, code string, at time.Time) (step int64, ok bool)
+	// Encrypt encrypts a secret so it can be stored
+	Encrypt(secret string) (encrypted string, err error)
+	// Decrypt decrypts a stored secret
+	Decrypt(encrypted string) (secret string, err error)
+}
+
+// rfc6238TotpManager implements RFC 6238 time-based one-time passwords with secrets encrypted using AES-GCM
+type rfc6238TotpManager struct {
+	issuer string
+	aead   cipher.AEAD
+}
+
+// NewTotpManager creates a new totp manager
+func NewTotpManager(cfg config.WeCreditConfig) (TotpManager, error) {
+	key, err := base64.StdEncoding.DecodeString(cfg.TotpEncryptionKey)
+	if err != nil || len(key) != 32 {
+		return nil, errors.New("TOTP_ENCRYPTION_KEY must be 32 bytes encoded in base64")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &rfc6238TotpManager{
+		issuer: cfg.TotpIssuer,
+		aead:   aead,
+	}, nil
+}
+
+func (m *rfc6238TotpManager) GenerateSecret() (secret string, err error) {
+	buf := make([]byte, totpSecretSize)
+	if _, err = rand.Read(buf); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(buf), nil
+}
+
+func (m *rfc6238TotpManager) ProvisioningURI(account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", m.issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+	label := escapeLabel(m.issuer) + ":" + escapeLabel(account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+func (m *rfc6238TotpManager) Validate(secret, code string, at time.Time) (step int64, ok bool) {
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+	current := at.Unix() / totpPeriod
+	for s := current - totpSkew; s <= current+totpSkew; s++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, s)), []byte(code)) == 1 {
+			return s, true
+		}
+	}
+	return 0, false
+}
+
+func (m *rfc6238TotpManager) Encrypt(secret string) (encrypted string, err error) {
+	nonce := make([]byte, m.aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := m.aead.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (m *rfc6238TotpManager) Decrypt(encrypted string) (secret string, err error) {
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < m.aead.NonceSize() {
+		return "", errors.New("invalid encrypted totp secret")
+	}
+	nonce, sealed := data[:m.aead.NonceSize()], data[m.aead.NonceSize():]
+	plain, err := m.aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+// escapeLabel escapes a part of the label, a plus sign of a phone number is escaped so apps do not read it as a space
+func escapeLabel(v string) string {
+	return strings.ReplaceAll(url.QueryEscape(v), "+", "%20")
+}
+
+// hotp computes the RFC 4226 code of the key for the counter
+func hotp(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
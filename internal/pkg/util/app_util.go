@@ -4,15 +4,9 @@ import (
 	"crypto/rand"
 	"fmt"
 	"io"
-	"log"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
-	"github.com/twilio/twilio-go"
-	openapi "github.com/twilio/twilio-go/rest/api/v2010"
-
-	"github.com/weCredit/internal/domain"
-	"github.com/weCredit/internal/pkg/config"
 )
 
 var daysOfWeek = map[string]time.Weekday{
@@ -32,6 +26,8 @@ type AppUtil interface {
 
 	// GenerateOTP ... Generate a one time password
 	GenerateOTP(length int) string
+	// GenerateCode ... Generate a random code of the given length using only characters of the alphabet
+	GenerateCode(length int, alphabet string) string
 	// GenerateUniqueToken ... Generate a unique token
 	GenerateUniqueToken() string
 	// GetExpiryTimeForDuration ... Get an expiry time based on the duration (in hours) passed
@@ -52,8 +48,6 @@ type AppUtil interface {
 	ParseWeekday(v string) (time.Weekday, error)
 	// IsTimeExpired ... Validate if the specified time has expired based on the current time
 	IsTimeExpired(t time.Time) bool
-	// SendOtp sends the otp using twilio
-	SendOtp(cfg config.WeCreditConfig, sms domain.OtpMessage) error
 }
 
 // NewAppUtil ... Creates a new AppUtil
@@ -68,19 +62,29 @@ func (as *simpleAppUtil) GetCurrentTime() time.Time {
 }
 
 func (as *simpleAppUtil) GenerateOTP(length int) string {
-	const digits = "1234567890"
-	if length <= 0 {
+	return as.GenerateCode(length, "1234567890")
+}
+
+func (as *simpleAppUtil) GenerateCode(length int, alphabet string) string {
+	if length <= 0 || len(alphabet) == 0 || len(alphabet) > 256 {
 		return ""
 	}
+	// Reject the random bytes above the largest multiple of the alphabet size, so every character is equally likely
+	limit := 256 - 256%len(alphabet)
+	result := make([]byte, 0, length)
 	buffer := make([]byte, length)
-	n, err := io.ReadAtLeast(rand.Reader, buffer, length)
-	if n != length || err != nil {
-		return ""
-	}
-	for i := 0; i < len(buffer); i++ {
-		buffer[i] = digits[int(buffer[i])%len(digits)]
+	for len(result) < length {
+		n, err := io.ReadAtLeast(rand.Reader, buffer, length)
+		if n != length || err != nil {
+			return ""
+		}
+		for _, b := range buffer {
+			if int(b) < limit && len(result) < length {
+				result = append(result, alphabet[int(b)%len(alphabet)])
+			}
+		}
 	}
-	return string(buffer)
+	return string(result)
 }
 
 func (as *simpleAppUtil) GenerateUniqueToken() string {
@@ -140,31 +144,3 @@ func (as *simpleAppUtil) ParseWeekday(v string) (time.Weekday, error) {
 
 	return time.Sunday, fmt.Errorf("invalid weekday '%s'", v)
 }
-
-func (as *simpleAppUtil) SendOtp(cfg config.WeCreditConfig, sms domain.OtpMessage) error {
-	// TODO: implement this
-	client := twilio.NewRestClientWithParams(twilio.ClientParams{
-		Username: cfg.AccountSSID,
-		Password: cfg.AccountAuthToken,
-	})
-
-	// Define a predefined SMS template
-	messageBody := fmt.Sprintf("Your OTP is: %s. Please use this to complete your login. Do not share this code with anyone.", sms.Otp)
-
-	// Prepare the message parameters
-	params := &openapi.CreateMessageParams{}
-	params.SetTo(sms.To)
-	params.SetFrom(cfg.TwilioNumber)
-	params.SetBody(messageBody)
-
-	// Send the message
-	resp, err := client.Api.CreateMessage(params)
-	if err != nil {
-		return fmt.Errorf("failed to send OTP: %w", err)
-	}
-
-	// Log the response for debugging
-	log.Printf("OTP sent successfully! SID: %s", *resp.Sid)
-	return nil
-
-}
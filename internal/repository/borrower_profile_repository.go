@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/weCredit/internal/domain"
+)
+
+type pgxBorrowerProfileRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBorrowerProfileRepository(db *pgxpool.Pool) domain.BorrowerProfileRepository {
+	return &pgxBorrowerProfileRepository{
+		db: db,
+	}
+}
+
+// FindByUserID implements domain.BorrowerProfileRepository.
+func (r *pgxBorrowerProfileRepository) FindByUserID(ctx context.Context, userID uuid.UUID) (result domain.BorrowerProfile, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Retrieve the data
+	q := `SELECT * FROM borrower_profiles WHERE user_id = $1 LIMIT 1`
+	args := []interface{}{userID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.BorrowerProfile])
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return result, domain.DataNotFoundError{}
+	}
+
+	return result, err
+}
+
+// Upsert implements domain.BorrowerProfileRepository.
+func (r *pgxBorrowerProfileRepository) Upsert(ctx context.Context, entity *domain.BorrowerProfile) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// The profile keeps its id and creation time when it is replaced
+	q := `INSERT INTO borrower_profiles (user_id, date_of_birth, gender, occupation, employer, current_address, permanent_address) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (user_id) DO UPDATE SET date_of_birth = EXCLUDED.date_of_birth, gender = EXCLUDED.gender, occupation = EXCLUDED.occupation,
+		employer = EXCLUDED.employer, current_address = EXCLUDED.current_address, permanent_address = EXCLUDED.permanent_address, updated_at = NOW()
+		RETURNING id, created_at, updated_at`
+	args := []interface{}{entity.UserID, entity.DateOfBirth, entity.Gender, entity.Occupation, entity.Employer, entity.CurrentAddress, entity.PermanentAddress}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	}
+
+	return err
+}
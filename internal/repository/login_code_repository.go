@@ -5,11 +5,15 @@ import (
 	"errors"
 	"github.com/gofrs/uuid/v5"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/weCredit/internal/domain"
 )
 
+// loginCodeHistoryMax defines how many codes of a username FindAllByUsername returns
+const loginCodeHistoryMax = 50
+
 type pgxLoginCodeRepository struct {
 	db *pgxpool.Pool
 }
@@ -21,6 +25,7 @@ func NewLoginCodeRepository(db *pgxpool.Pool) domain.LoginCodeRepository {
 }
 
 func (r pgxLoginCodeRepository) FindByID(ctx context.Context, id uuid.UUID) (result domain.LoginCode, err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -55,15 +60,71 @@ func (r pgxLoginCodeRepository) FindByID(ctx context.Context, id uuid.UUID) (res
 	return result, err
 }
 
-func (r pgxLoginCodeRepository) FindByUsername(ctx context.Context, username string) (result domain.LoginCode, err error) {
+func (r pgxLoginCodeRepository) FindAllByUsername(ctx context.Context, username string) (result []domain.LoginCode, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Used and replaced codes are soft-deleted, they stay listed for their delivery receipts
+	q := `SELECT * FROM login_codes WHERE username = $1 ORDER BY created_at DESC LIMIT $2`
+	args := []interface{}{username, loginCodeHistoryMax}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.LoginCode])
+}
+
+func (r pgxLoginCodeRepository) FindByMessageID(ctx context.Context, messageID string) (result domain.LoginCode, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Used codes are soft-deleted and still get their delivery reports
+	q := `SELECT * FROM login_codes WHERE message_id = $1 LIMIT 1`
+	args := []interface{}{messageID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.LoginCode])
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return result, domain.DataNotFoundError{}
+	}
+
+	return result, err
+}
+
+func (r pgxLoginCodeRepository) FindByUsername(ctx context.Context, username string, purpose domain.LoginCodePurpose) (result domain.LoginCode, err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
 
 	// Retrieve the data
-	q := `SELECT * FROM login_codes WHERE username = $1 AND deleted_at IS NULL LIMIT 1`
-	args := []interface{}{username}
+	q := `SELECT * FROM login_codes WHERE username = $1 AND purpose = $2 AND deleted_at IS NULL LIMIT 1`
+	args := []interface{}{username, purpose}
 	var rows pgx.Rows
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
@@ -89,14 +150,15 @@ func (r pgxLoginCodeRepository) FindByUsername(ctx context.Context, username str
 }
 
 func (r pgxLoginCodeRepository) Create(ctx context.Context, entity *domain.LoginCode) (err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
 
 	// Create the data
-	q := `INSERT INTO login_codes (username, code, expiry_time, status) VALUES ($1, $2, $3, $4) RETURNING id, created_at, updated_at`
-	args := []interface{}{entity.Username, entity.Code, entity.ExpiryTime, entity.Status}
+	q := `INSERT INTO login_codes (username, code, expiry_time, status, purpose, target, last_sent_at, message_id, response_meta) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at`
+	args := []interface{}{entity.Username, entity.Code, entity.ExpiryTime, entity.Status, entity.Purpose, entity.Target, entity.LastSentAt, entity.MessageID, entity.ResponseMeta}
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
 		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
@@ -108,14 +170,15 @@ func (r pgxLoginCodeRepository) Create(ctx context.Context, entity *domain.Login
 }
 
 func (r pgxLoginCodeRepository) Update(ctx context.Context, id uuid.UUID, entity *domain.LoginCode) (err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
 
 	// Update the data
-	q := `UPDATE login_codes SET username=$1, code=$2, expiry_time=$3, status=$4, updated_at=NOW() WHERE id=$5 RETURNING updated_at`
-	args := []interface{}{entity.Username, entity.Code, entity.ExpiryTime, entity.Status, id}
+	q := `UPDATE login_codes SET username=$1, code=$2, expiry_time=$3, status=$4, purpose=$5, target=$6, attempts=$7, locked_until=$8, last_sent_at=$9, message_id=$10, response_meta=$11, updated_at=NOW() WHERE id=$12 RETURNING updated_at`
+	args := []interface{}{entity.Username, entity.Code, entity.ExpiryTime, entity.Status, entity.Purpose, entity.Target, entity.Attempts, entity.LockedUntil, entity.LastSentAt, entity.MessageID, entity.ResponseMeta, id}
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
 		err = tx.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
@@ -126,7 +189,78 @@ func (r pgxLoginCodeRepository) Update(ctx context.Context, id uuid.UUID, entity
 	return err
 }
 
+func (r pgxLoginCodeRepository) UpdateDeliveryReceipt(ctx context.Context, id uuid.UUID, messageID *string, meta string) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Update the data
+	q := `UPDATE login_codes SET message_id = $1, response_meta = $2, updated_at = NOW() WHERE id = $3`
+	args := []interface{}{messageID, meta, id}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+
+	return err
+}
+
+func (r pgxLoginCodeRepository) IncrementAttempts(ctx context.Context, id uuid.UUID) (attempts int, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Increment the attempts atomically so concurrent guesses are all counted
+	q := `UPDATE login_codes SET attempts = attempts + 1, updated_at = NOW() WHERE id = $1 RETURNING attempts`
+	args := []interface{}{id}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&attempts)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&attempts)
+	}
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return attempts, domain.DataNotFoundError{}
+	}
+
+	return attempts, err
+}
+
+func (r pgxLoginCodeRepository) Consume(ctx context.Context, id uuid.UUID) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Only a pending record can be consumed, the row lock makes concurrent requests wait and then find nothing
+	q := `UPDATE login_codes SET status = $1, deleted_at = NOW(), updated_at = NOW() WHERE id = $2 AND status = $3 AND deleted_at IS NULL`
+	args := []interface{}{domain.LoginCodeStatusSUCCESS, id, domain.LoginCodeStatusPENDING}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
+
 func (r pgxLoginCodeRepository) Delete(ctx context.Context, id uuid.UUID) (err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -145,15 +279,16 @@ func (r pgxLoginCodeRepository) Delete(ctx context.Context, id uuid.UUID) (err e
 	return err
 }
 
-func (r pgxLoginCodeRepository) DeleteByUsername(ctx context.Context, username string) (err error) {
+func (r pgxLoginCodeRepository) DeleteByUsername(ctx context.Context, username string, purpose domain.LoginCodePurpose) (err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
 
-	// Delete the data
-	q := `DELETE FROM login_codes WHERE username = $1`
-	args := []interface{}{username}
+	// Soft-delete the data so the delivery receipts are kept
+	q := `UPDATE login_codes SET deleted_at = NOW(), updated_at = NOW() WHERE username = $1 AND purpose = $2 AND deleted_at IS NULL`
+	args := []interface{}{username, purpose}
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
 		_, err = tx.Exec(ctx, q, args...)
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/weCredit/internal/domain"
+)
+
+type pgxLoginEventRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLoginEventRepository(db *pgxpool.Pool) domain.LoginEventRepository {
+	return &pgxLoginEventRepository{
+		db: db,
+	}
+}
+
+// Create implements domain.LoginEventRepository.
+func (r *pgxLoginEventRepository) Create(ctx context.Context, entity *domain.LoginEvent) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Create the data
+	q := `INSERT INTO login_events (user_id, username, outcome, channel, ip_address, user_agent, request_id) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at`
+	args := []interface{}{entity.UserID, entity.Username, entity.Outcome, entity.Channel, entity.IPAddress, entity.UserAgent, entity.RequestID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	}
+
+	return err
+}
+
+// FindAll implements domain.LoginEventRepository.
+func (r *pgxLoginEventRepository) FindAll(ctx context.Context, filter domain.LoginEventFilter) (result []domain.LoginEvent, total int64, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Build the conditions of the filter
+	conds := []string{"TRUE"}
+	args := []interface{}{}
+	where := func(cond string, v interface{}) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if filter.UserID != nil {
+		where("user_id = $%d", *filter.UserID)
+	}
+	if filter.Username != "" {
+		where("username = $%d", filter.Username)
+	}
+	if filter.Outcome != "" {
+		where("outcome = $%d", filter.Outcome)
+	}
+	if filter.IPAddress != "" {
+		where("ip_address = $%d", filter.IPAddress)
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $%d", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $%d", filter.To)
+	}
+	cond := strings.Join(conds, " AND ")
+
+	// Count the matching events
+	q := `SELECT COUNT(*) FROM login_events WHERE ` + cond
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&total)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&total)
+	}
+	if err != nil {
+		return result, 0, err
+	}
+
+	// Retrieve the page
+	q = fmt.Sprintf(`SELECT * FROM login_events WHERE %s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, cond, len(args)+1, len(args)+2)
+	args = append(args, filter.Size, (filter.Page-1)*filter.Size)
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, 0, err
+	}
+	defer rows.Close()
+
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.LoginEvent])
+	return result, total, err
+}
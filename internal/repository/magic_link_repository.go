@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/weCredit/internal/domain"
+)
+
+type pgxMagicLinkRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMagicLinkRepository(db *pgxpool.Pool) domain.MagicLinkRepository {
+	return &pgxMagicLinkRepository{
+		db: db,
+	}
+}
+
+// FindByTokenHash implements domain.MagicLinkRepository.
+func (r *pgxMagicLinkRepository) FindByTokenHash(ctx context.Context, hash string) (result domain.MagicLink, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Retrieve the data
+	q := `SELECT * FROM magic_links WHERE token_hash = $1 LIMIT 1`
+	args := []interface{}{hash}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.MagicLink])
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return result, domain.DataNotFoundError{}
+	}
+
+	return result, err
+}
+
+// Create implements domain.MagicLinkRepository.
+func (r *pgxMagicLinkRepository) Create(ctx context.Context, entity *domain.MagicLink) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Create the data
+	q := `INSERT INTO magic_links (user_id, token_hash, binding_hash, fingerprint, channel, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at`
+	args := []interface{}{entity.UserID, entity.TokenHash, entity.BindingHash, entity.Fingerprint, entity.Channel, entity.ExpiresAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	}
+
+	return err
+}
+
+// MarkUsed implements domain.MagicLinkRepository.
+func (r *pgxMagicLinkRepository) MarkUsed(ctx context.Context, id uuid.UUID) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Only an unused link can be used, so two concurrent requests cannot both log in with it
+	q := `UPDATE magic_links SET used_at = NOW(), updated_at = NOW() WHERE id = $1 AND used_at IS NULL`
+	args := []interface{}{id}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
+
+// ExpireByUserID implements domain.MagicLinkRepository.
+func (r *pgxMagicLinkRepository) ExpireByUserID(ctx context.Context, userID uuid.UUID) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE magic_links SET used_at = NOW(), updated_at = NOW() WHERE user_id = $1 AND used_at IS NULL`
+	args := []interface{}{userID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+
+	return err
+}
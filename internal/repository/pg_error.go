@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"errors"
+	"log/slog"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/weCredit/internal/domain"
+)
+
+// SQLSTATE codes of the postgres errors translated into domain errors
+const (
+	pgCodeNotNullViolation     = "23502"
+	pgCodeForeignKeyViolation  = "23503"
+	pgCodeUniqueViolation      = "23505"
+	pgCodeCheckViolation       = "23514"
+	pgCodeSerializationFailure = "40001"
+	pgCodeDeadlockDetected     = "40P01"
+)
+
+// uniqueViolationErrors maps unique constraints to the conflict reported to the client
+var uniqueViolationErrors = map[string]domain.ConflictError{
+	"users_user_name_idx": {Code: domain.ErrorCodeUSERNAME_EXISTS, Message: domain.MessageUSERNAMEEREXISTS},
+	"users_email_idx":     {Code: domain.ErrorCodeEMAIL_EXISTS, Message: domain.MessageEMAILEXISTS},
+}
+
+// translatePgError replaces a postgres constraint or concurrency error with a typed domain error,
+// so table and constraint names never reach the client. Other errors are left untouched.
+func translatePgError(err *error) {
+	var pgErr *pgconn.PgError
+	if err == nil || !errors.As(*err, &pgErr) {
+		return
+	}
+	switch pgErr.Code {
+	case pgCodeUniqueViolation:
+		if cErr, ok := uniqueViolationErrors[pgErr.ConstraintName]; ok {
+			*err = cErr
+			return
+		}
+		*err = domain.ConflictError{Code: domain.ErrorCodeALREADY_EXISTS, Message: domain.MessageALREADYEXISTS}
+	case pgCodeForeignKeyViolation:
+		*err = domain.UserError{Code: domain.ErrorCodeINVALID_REFERENCE, Message: domain.MessageINVALIDREFERENCE}
+	case pgCodeCheckViolation, pgCodeNotNullViolation:
+		*err = domain.UserError{Code: domain.ErrorCodeCONSTRAINT_VIOLATION, Message: domain.MessageCONSTRAINTVIOLATION}
+	case pgCodeSerializationFailure, pgCodeDeadlockDetected:
+		*err = domain.ConflictError{Code: domain.ErrorCodeCONCURRENT_UPDATE, Message: domain.MessageCONCURRENTUPDATE}
+	default:
+		return
+	}
+	// The constraint name is only logged, it tells which rule was broken without exposing it
+	slog.Warn("database constraint or concurrency error", "code", pgErr.Code, "constraint", pgErr.ConstraintName, "table", pgErr.TableName)
+}
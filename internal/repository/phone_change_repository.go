@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/weCredit/internal/domain"
+)
+
+type pgxPhoneChangeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPhoneChangeRepository(db *pgxpool.Pool) domain.PhoneChangeRepository {
+	return &pgxPhoneChangeRepository{
+		db: db,
+	}
+}
+
+// Create implements domain.PhoneChangeRepository.
+func (r *pgxPhoneChangeRepository) Create(ctx context.Context, entity *domain.PhoneChange) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Create the data
+	q := `INSERT INTO phone_changes (user_id, old_user_name, new_user_name, method, actor_id, reason, ip_address, user_agent, request_id) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at`
+	args := []interface{}{entity.UserID, entity.OldUserName, entity.NewUserName, entity.Method, entity.ActorID, entity.Reason, entity.IPAddress, entity.UserAgent, entity.RequestID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt)
+	}
+
+	return err
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/weCredit/internal/domain"
+)
+
+type pgxRateLimitRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRateLimitRepository(db *pgxpool.Pool) domain.RateLimitRepository {
+	return &pgxRateLimitRepository{
+		db: db,
+	}
+}
+
+// Hit implements domain.RateLimitRepository.
+func (r *pgxRateLimitRepository) Hit(ctx context.Context, key string, limit int, window time.Duration) (allowed bool, retryAfter time.Duration, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// The check and the insert must be atomic, so hits for the same key are serialized with an advisory lock
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, 0, err
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback(ctx)
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtext($1))`, key)
+	if err != nil {
+		return false, 0, err
+	}
+	now := time.Now()
+	windowStart := now.Add(-window)
+
+	// Find the hit that has to leave the window before another one is allowed
+	var oldest *time.Time
+	var count int
+	q := `SELECT COUNT(*), (SELECT hit_at FROM rate_limit_hits WHERE key = $1 AND hit_at > $2 ORDER BY hit_at DESC OFFSET $3 - 1 LIMIT 1) FROM rate_limit_hits WHERE key = $1 AND hit_at > $2`
+	err = tx.QueryRow(ctx, q, key, windowStart, limit).Scan(&count, &oldest)
+	if err != nil {
+		return false, 0, err
+	}
+	if count >= limit {
+		if oldest != nil {
+			retryAfter = oldest.Add(window).Sub(now)
+		}
+		return false, retryAfter, tx.Commit(ctx)
+	}
+
+	_, err = tx.Exec(ctx, `INSERT INTO rate_limit_hits (key, hit_at) VALUES ($1, $2)`, key, now)
+	if err != nil {
+		return false, 0, err
+	}
+	return true, 0, tx.Commit(ctx)
+}
+
+// DeleteBefore implements domain.RateLimitRepository.
+func (r *pgxRateLimitRepository) DeleteBefore(ctx context.Context, before time.Time) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `DELETE FROM rate_limit_hits WHERE hit_at < $1`
+	args := []interface{}{before}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+
+	return err
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/weCredit/internal/domain"
+)
+
+type pgxRecoveryCodeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRecoveryCodeRepository(db *pgxpool.Pool) domain.RecoveryCodeRepository {
+	return &pgxRecoveryCodeRepository{
+		db: db,
+	}
+}
+
+// Create implements domain.RecoveryCodeRepository.
+func (r *pgxRecoveryCodeRepository) Create(ctx context.Context, entity *domain.RecoveryCode) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Create the data
+	q := `INSERT INTO recovery_codes (user_id, code_hash) VALUES ($1, $2) RETURNING id, created_at, updated_at`
+	args := []interface{}{entity.UserID, entity.CodeHash}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	}
+
+	return err
+}
+
+// Use implements domain.RecoveryCodeRepository.
+func (r *pgxRecoveryCodeRepository) Use(ctx context.Context, userID uuid.UUID, codeHash string) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Only an unused code can be used, so two concurrent logins cannot both spend it
+	q := `UPDATE recovery_codes SET used_at = NOW(), updated_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+	args := []interface{}{userID, codeHash}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
+
+// DeleteByUserID implements domain.RecoveryCodeRepository.
+func (r *pgxRecoveryCodeRepository) DeleteByUserID(ctx context.Context, userID uuid.UUID) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `DELETE FROM recovery_codes WHERE user_id = $1`
+	args := []interface{}{userID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+
+	return err
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/weCredit/internal/domain"
+)
+
+type pgxRevokedTokenRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRevokedTokenRepository(db *pgxpool.Pool) domain.RevokedTokenRepository {
+	return &pgxRevokedTokenRepository{
+		db: db,
+	}
+}
+
+// Create implements domain.RevokedTokenRepository.
+func (r *pgxRevokedTokenRepository) Create(ctx context.Context, entity *domain.RevokedToken) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Revoking the same token twice is not an error
+	q := `INSERT INTO revoked_tokens (jti, user_id, expires_at) VALUES ($1, $2, $3) ON CONFLICT (jti) DO UPDATE SET expires_at = EXCLUDED.expires_at RETURNING created_at`
+	args := []interface{}{entity.TokenID, entity.UserID, entity.ExpiresAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.CreatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.CreatedAt)
+	}
+
+	return err
+}
+
+// Exists implements domain.RevokedTokenRepository.
+func (r *pgxRevokedTokenRepository) Exists(ctx context.Context, tokenID string) (result bool, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `SELECT EXISTS(SELECT 1 FROM revoked_tokens WHERE jti = $1)`
+	args := []interface{}{tokenID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&result)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&result)
+	}
+
+	return result, err
+}
+
+// DeleteExpired implements domain.RevokedTokenRepository.
+func (r *pgxRevokedTokenRepository) DeleteExpired(ctx context.Context) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `DELETE FROM revoked_tokens WHERE expires_at < NOW()`
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q)
+	} else {
+		_, err = r.db.Exec(ctx, q)
+	}
+
+	return err
+}
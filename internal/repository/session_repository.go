@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/weCredit/internal/domain"
+)
+
+type pgxSessionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSessionRepository(db *pgxpool.Pool) domain.SessionRepository {
+	return &pgxSessionRepository{
+		db: db,
+	}
+}
+
+// FindByRefreshTokenHash implements domain.SessionRepository.
+func (r *pgxSessionRepository) FindByRefreshTokenHash(ctx context.Context, hash string) (result domain.Session, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Retrieve the data
+	q := `SELECT * FROM sessions WHERE refresh_token_hash = $1 LIMIT 1`
+	args := []interface{}{hash}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.Session])
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return result, domain.DataNotFoundError{}
+	}
+
+	return result, err
+}
+
+// Create implements domain.SessionRepository.
+func (r *pgxSessionRepository) Create(ctx context.Context, entity *domain.Session) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Create the data
+	q := `INSERT INTO sessions (user_id, family_id, user_device_id, refresh_token_hash, device, ip_address, user_agent, mfa, auth_time, expires_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10) RETURNING id, created_at, updated_at`
+	args := []interface{}{entity.UserID, entity.FamilyID, entity.UserDeviceID, entity.RefreshTokenHash, entity.Device, entity.IPAddress, entity.UserAgent, entity.Mfa, entity.AuthTime, entity.ExpiresAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	}
+
+	return err
+}
+
+// MarkRotated implements domain.SessionRepository.
+func (r *pgxSessionRepository) MarkRotated(ctx context.Context, id uuid.UUID) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Only an active session can be rotated, so two concurrent refreshes cannot both succeed
+	q := `UPDATE sessions SET rotated_at = NOW(), updated_at = NOW() WHERE id = $1 AND rotated_at IS NULL AND revoked_at IS NULL`
+	args := []interface{}{id}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
+
+// UpdateAuthTime implements domain.SessionRepository.
+func (r *pgxSessionRepository) UpdateAuthTime(ctx context.Context, familyID uuid.UUID, at time.Time) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE sessions SET auth_time = $1, updated_at = NOW() WHERE family_id = $2 AND rotated_at IS NULL AND revoked_at IS NULL`
+	args := []interface{}{at, familyID}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
+
+// RevokeFamily implements domain.SessionRepository.
+func (r *pgxSessionRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE sessions SET revoked_at = NOW(), updated_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+	args := []interface{}{familyID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+
+	return err
+}
+
+// RevokeByUserDeviceID implements domain.SessionRepository.
+func (r *pgxSessionRepository) RevokeByUserDeviceID(ctx context.Context, userDeviceID uuid.UUID) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE sessions SET revoked_at = NOW(), updated_at = NOW() WHERE user_device_id = $1 AND revoked_at IS NULL`
+	args := []interface{}{userDeviceID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+
+	return err
+}
+
+// RevokeByUserID implements domain.SessionRepository.
+func (r *pgxSessionRepository) RevokeByUserID(ctx context.Context, userID uuid.UUID) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE sessions SET revoked_at = NOW(), updated_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	args := []interface{}{userID}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+
+	return err
+}
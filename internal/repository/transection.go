@@ -36,6 +36,8 @@ func (t *transactioner) Begin(ctx context.Context) (result context.Context, err
 }
 
 func (t *transactioner) Commit(ctx context.Context) (err error) {
+	// Serialization failures of serializable transactions surface at commit
+	defer translatePgError(&err)
 	tx, ok := ctx.Value(TxKey).(*pgxpool.Tx)
 	if !ok {
 		return err
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/gofrs/uuid/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/weCredit/internal/domain"
+)
+
+type pgxUserDeviceRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewUserDeviceRepository(db *pgxpool.Pool) domain.UserDeviceRepository {
+	return &pgxUserDeviceRepository{
+		db: db,
+	}
+}
+
+// FindByFingerprint implements domain.UserDeviceRepository.
+func (r *pgxUserDeviceRepository) FindByFingerprint(ctx context.Context, userID uuid.UUID, deviceID, fingerprint string) (result domain.UserDevice, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Retrieve the data
+	q := `SELECT * FROM user_devices WHERE user_id = $1 AND device_id = $2 AND fingerprint = $3 LIMIT 1`
+	args := []interface{}{userID, deviceID, fingerprint}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.UserDevice])
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return result, domain.DataNotFoundError{}
+	}
+
+	return result, err
+}
+
+// FindAllByUserID implements domain.UserDeviceRepository.
+func (r *pgxUserDeviceRepository) FindAllByUserID(ctx context.Context, userID uuid.UUID) (result []domain.UserDevice, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Retrieve the data
+	q := `SELECT * FROM user_devices WHERE user_id = $1 ORDER BY last_seen_at DESC`
+	args := []interface{}{userID}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, err
+	}
+	defer rows.Close()
+
+	return pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.UserDevice])
+}
+
+// Create implements domain.UserDeviceRepository.
+func (r *pgxUserDeviceRepository) Create(ctx context.Context, entity *domain.UserDevice) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Create the data
+	q := `INSERT INTO user_devices (user_id, device_id, fingerprint, name, user_agent, last_ip_address, last_seen_at) VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id, created_at, updated_at`
+	args := []interface{}{entity.UserID, entity.DeviceID, entity.Fingerprint, entity.Name, entity.UserAgent, entity.LastIPAddress, entity.LastSeenAt}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.ID, &entity.CreatedAt, &entity.UpdatedAt)
+	}
+
+	return err
+}
+
+// Update implements domain.UserDeviceRepository.
+func (r *pgxUserDeviceRepository) Update(ctx context.Context, id uuid.UUID, entity *domain.UserDevice) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Update the data
+	q := `UPDATE user_devices SET name = $1, user_agent = $2, last_ip_address = $3, last_seen_at = $4, revoked_at = $5, updated_at = NOW() WHERE id = $6 RETURNING updated_at`
+	args := []interface{}{entity.Name, entity.UserAgent, entity.LastIPAddress, entity.LastSeenAt, entity.RevokedAt, id}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&entity.UpdatedAt)
+	}
+
+	return err
+}
+
+// Revoke implements domain.UserDeviceRepository.
+func (r *pgxUserDeviceRepository) Revoke(ctx context.Context, userID, id uuid.UUID) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE user_devices SET revoked_at = NOW(), updated_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	args := []interface{}{id, userID}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
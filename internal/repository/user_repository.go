@@ -3,9 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/gofrs/uuid/v5"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/weCredit/internal/domain"
@@ -23,6 +27,7 @@ func NewUserRepository(db *pgxpool.Pool) domain.UserRepository {
 
 // CreateUser implements domain.UserRepository.
 func (r *pgxUserRepository) CreateUser(ctx context.Context, entity *domain.User) (err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
@@ -40,31 +45,70 @@ func (r *pgxUserRepository) CreateUser(ctx context.Context, entity *domain.User)
 
 // DeleteUser implements domain.UserRepository.
 func (r *pgxUserRepository) DeleteUser(ctx context.Context, id uuid.UUID) (err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
 
-	q := `UPDATE users SET deleted_at = NOW() WHERE id = $1`
+	q := `UPDATE users SET status = 'DELETED', status_changed_at = NOW(), deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND deleted_at IS NULL`
 	args := []interface{}{id}
+	var tag pgconn.CommandTag
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
-		_, err = tx.Exec(ctx, q, args...)
+		tag, err = tx.Exec(ctx, q, args...)
 	} else {
-		_, err = r.db.Exec(ctx, q, args...)
+		tag, err = r.db.Exec(ctx, q, args...)
 	}
-	return err
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+	return nil
+}
+
+// UpdateStatus implements domain.UserRepository.
+func (r *pgxUserRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from, to domain.UserStatus, reason *string) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Only move a user that is still in the status the change was decided on, deleted_at follows the status
+	q := `UPDATE users SET status = $1, status_reason = $2, status_changed_at = NOW(),
+		deleted_at = CASE WHEN $1 = 'DELETED' THEN NOW() ELSE NULL END, updated_at = NOW()
+		WHERE id = $3 AND status = $4`
+	args := []interface{}{string(to), reason, id, string(from)}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
 }
 
 // FindByID implements domain.UserRepository.
 func (r *pgxUserRepository) FindByID(ctx context.Context, id uuid.UUID) (result domain.User, err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
 
 	// Retrieve the data
-	q := `SELECT * FROM users WHERE id = $1  LIMIT 1`
+	q := `SELECT * FROM users WHERE id = $1 AND deleted_at IS NULL LIMIT 1`
 	args := []interface{}{id}
 	var rows pgx.Rows
 	if txVal != nil {
@@ -84,21 +128,53 @@ func (r *pgxUserRepository) FindByID(ctx context.Context, id uuid.UUID) (result
 
 	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.User])
 	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return result, domain.DataNotFoundError{}
+	}
+
+	return result, err
+}
+
+// FindByIDIncludingDeleted implements domain.UserRepository.
+func (r *pgxUserRepository) FindByIDIncludingDeleted(ctx context.Context, id uuid.UUID) (result domain.User, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Retrieve the data
+	q := `SELECT * FROM users WHERE id = $1 LIMIT 1`
+	args := []interface{}{id}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
 		return result, err
 	}
+	defer rows.Close()
+
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.User])
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return result, domain.DataNotFoundError{}
+	}
 
 	return result, err
 }
 
 // FindByUserName implements domain.UserRepository.
 func (r *pgxUserRepository) FindByUserName(ctx context.Context, username string) (result domain.User, err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
 
 	// Retrieve the data
-	q := `SELECT * FROM users WHERE user_name = $1  LIMIT 1`
+	q := `SELECT * FROM users WHERE user_name = $1 AND deleted_at IS NULL LIMIT 1`
 	args := []interface{}{username}
 	var rows pgx.Rows
 	if txVal != nil {
@@ -118,21 +194,135 @@ func (r *pgxUserRepository) FindByUserName(ctx context.Context, username string)
 
 	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.User])
 	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return result, domain.DataNotFoundError{}
+	}
+
+	return result, err
+}
+
+// FindByEmail implements domain.UserRepository.
+func (r *pgxUserRepository) FindByEmail(ctx context.Context, email string) (result domain.User, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Like the mobile number, the email of a deleted user is released
+	q := `SELECT * FROM users WHERE LOWER(email) = LOWER($1) AND deleted_at IS NULL LIMIT 1`
+	args := []interface{}{email}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	defer rows.Close()
+
+	if err != nil {
 		return result, err
 	}
 
+	result, err = pgx.CollectOneRow(rows, pgx.RowToStructByNameLax[domain.User])
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return result, domain.DataNotFoundError{}
+	}
+
 	return result, err
 }
 
+// FindAll implements domain.UserRepository.
+func (r *pgxUserRepository) FindAll(ctx context.Context, filter domain.UserFilter) (result []domain.User, total int64, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Build the conditions of the filter
+	conds := []string{"TRUE"}
+	args := []interface{}{}
+	where := func(cond string, v interface{}) {
+		args = append(args, v)
+		conds = append(conds, strings.ReplaceAll(cond, "$?", fmt.Sprintf("$%d", len(args))))
+	}
+	if filter.Status != "" {
+		where("status = $?", filter.Status)
+	} else {
+		conds = append(conds, "deleted_at IS NULL")
+	}
+	if filter.Role != "" {
+		where("role = $?", filter.Role)
+	}
+	if filter.Search != "" {
+		where(`(full_name ILIKE $? ESCAPE '\' OR user_name ILIKE $? ESCAPE '\' OR email ILIKE $? ESCAPE '\')`, "%"+escapeLike(filter.Search)+"%")
+	}
+	if !filter.From.IsZero() {
+		where("created_at >= $?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		where("created_at < $?", filter.To)
+	}
+	cond := strings.Join(conds, " AND ")
+
+	// Count the matching users
+	q := `SELECT COUNT(*) FROM users WHERE ` + cond
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		err = tx.QueryRow(ctx, q, args...).Scan(&total)
+	} else {
+		err = r.db.QueryRow(ctx, q, args...).Scan(&total)
+	}
+	if err != nil {
+		return result, 0, err
+	}
+
+	// The sort field is checked by domain.UserSortField.IsValid, so it is safe to put in the query.
+	// The id breaks ties, so pages do not overlap.
+	sortBy := domain.UserSortFieldCREATED_AT
+	if filter.SortBy.IsValid() {
+		sortBy = filter.SortBy
+	}
+	order := "ASC"
+	if filter.SortDesc {
+		order = "DESC"
+	}
+
+	// Retrieve the page
+	q = fmt.Sprintf(`SELECT * FROM users WHERE %s ORDER BY %s %s, id %s LIMIT $%d OFFSET $%d`, cond, sortBy, order, order, len(args)+1, len(args)+2)
+	args = append(args, filter.Size, (filter.Page-1)*filter.Size)
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	if err != nil {
+		return result, 0, err
+	}
+	defer rows.Close()
+
+	result, err = pgx.CollectRows(rows, pgx.RowToStructByNameLax[domain.User])
+	return result, total, err
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, so the value only matches itself
+func escapeLike(v string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(v)
+}
+
 // UpdateUser implements domain.UserRepository.
 func (r *pgxUserRepository) UpdateUser(ctx context.Context, entity *domain.User) (err error) {
+	defer translatePgError(&err)
 	if ctx == nil {
 		ctx = context.Background()
 	}
 	txVal := ctx.Value(TxKey)
 
 	// Update the data
-	q := `UPDATE users SET full_name = $1, username = $2,  role = $3,  updated_at = NOW() WHERE id = $4 RETURNING updated_at`
+	q := `UPDATE users SET full_name = $1, user_name = $2, role = $3, updated_at = NOW() WHERE id = $4 AND deleted_at IS NULL RETURNING updated_at`
 	args := []interface{}{entity.FullName, entity.UserName, entity.Role, entity.ID}
 	if txVal != nil {
 		tx := txVal.(pgx.Tx)
@@ -143,3 +333,218 @@ func (r *pgxUserRepository) UpdateUser(ctx context.Context, entity *domain.User)
 
 	return err
 }
+
+// UpdateRole implements domain.UserRepository.
+func (r *pgxUserRepository) UpdateRole(ctx context.Context, id uuid.UUID, role domain.UserRole) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL`
+	args := []interface{}{role, id}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
+
+// UpdateFullName implements domain.UserRepository.
+func (r *pgxUserRepository) UpdateFullName(ctx context.Context, id uuid.UUID, fullName string) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE users SET full_name = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL`
+	args := []interface{}{fullName, id}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
+
+// FindPreferencesForUpdate implements domain.UserRepository.
+func (r *pgxUserRepository) FindPreferencesForUpdate(ctx context.Context, id uuid.UUID) (result domain.JSONB, err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `SELECT preferences FROM users WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`
+	args := []interface{}{id}
+	var rows pgx.Rows
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		rows, err = tx.Query(ctx, q, args...)
+	} else {
+		rows, err = r.db.Query(ctx, q, args...)
+	}
+	defer rows.Close()
+
+	if err != nil {
+		return result, err
+	}
+
+	result, err = pgx.CollectOneRow(rows, pgx.RowTo[domain.JSONB])
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		return result, domain.DataNotFoundError{}
+	}
+
+	return result, err
+}
+
+// UpdatePreferences implements domain.UserRepository.
+func (r *pgxUserRepository) UpdatePreferences(ctx context.Context, id uuid.UUID, preferences domain.JSONB) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE users SET preferences = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL`
+	args := []interface{}{preferences, id}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
+
+// UpdateEmail implements domain.UserRepository.
+func (r *pgxUserRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string, verifiedAt time.Time) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE users SET email = $1, email_verified_at = $2, updated_at = NOW() WHERE id = $3 AND deleted_at IS NULL`
+	args := []interface{}{email, verifiedAt, id}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
+
+// UpdateTokensValidAfter implements domain.UserRepository.
+func (r *pgxUserRepository) UpdateTokensValidAfter(ctx context.Context, id uuid.UUID, at time.Time) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Deleted users are not filtered, so their tokens can still be revoked
+	q := `UPDATE users SET tokens_valid_after = $1, updated_at = NOW() WHERE id = $2`
+	args := []interface{}{at, id}
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		_, err = tx.Exec(ctx, q, args...)
+	} else {
+		_, err = r.db.Exec(ctx, q, args...)
+	}
+
+	return err
+}
+
+// UpdateTotp implements domain.UserRepository.
+func (r *pgxUserRepository) UpdateTotp(ctx context.Context, id uuid.UUID, secret *string, enabledAt *time.Time) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	q := `UPDATE users SET totp_secret = $1, totp_enabled_at = $2, updated_at = NOW() WHERE id = $3 AND deleted_at IS NULL`
+	args := []interface{}{secret, enabledAt, id}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
+
+// UpdateTotpLastStep implements domain.UserRepository.
+func (r *pgxUserRepository) UpdateTotpLastStep(ctx context.Context, id uuid.UUID, step int64) (err error) {
+	defer translatePgError(&err)
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	txVal := ctx.Value(TxKey)
+
+	// Only move forward, so a code cannot be replayed within its validity window
+	q := `UPDATE users SET totp_last_step = $1, updated_at = NOW() WHERE id = $2 AND deleted_at IS NULL AND (totp_last_step IS NULL OR totp_last_step < $1)`
+	args := []interface{}{step, id}
+	var tag pgconn.CommandTag
+	if txVal != nil {
+		tx := txVal.(pgx.Tx)
+		tag, err = tx.Exec(ctx, q, args...)
+	} else {
+		tag, err = r.db.Exec(ctx, q, args...)
+	}
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.DataNotFoundError{}
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/weCredit/internal/domain"
+)
+
+const (
+	// borrowerMinAge is the age in years from which a user can borrow
+	borrowerMinAge = 18
+	// borrowerMaxAge rejects dates of birth that can only be typos
+	borrowerMaxAge = 100
+)
+
+type BorrowerProfileService struct {
+	bpr domain.BorrowerProfileRepository
+}
+
+func NewBorrowerProfileService(bpr domain.BorrowerProfileRepository) domain.BorrowerProfileService {
+	return &BorrowerProfileService{
+		bpr: bpr,
+	}
+}
+
+// FindByUserID implements domain.BorrowerProfileService.
+func (s *BorrowerProfileService) FindByUserID(userID uuid.UUID) (result domain.BorrowerProfile, err error) {
+	return s.bpr.FindByUserID(context.Background(), userID)
+}
+
+// Save implements domain.BorrowerProfileService.
+func (s *BorrowerProfileService) Save(in domain.SaveBorrowerProfileInput) (result domain.BorrowerProfile, err error) {
+	dob, err := time.Parse(domain.DateLayout, in.DateOfBirth)
+	if err != nil {
+		return result, err
+	}
+	now := time.Now()
+	if dob.AddDate(borrowerMinAge, 0, 0).After(now) || !dob.AddDate(borrowerMaxAge, 0, 0).After(now) {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_BIRTH_DATE, Message: domain.MessageINVALIDBIRTHDATE}
+	}
+	employer := strings.TrimSpace(in.Employer)
+	if in.Occupation == domain.OccupationSALARIED && employer == "" {
+		return result, domain.UserError{Code: domain.ErrorCodeEMPLOYER_REQUIRED, Message: domain.MessageEMPLOYERREQUIRED}
+	}
+	current, err := normalizeAddress(in.CurrentAddress)
+	if err != nil {
+		return result, err
+	}
+	permanent := current
+	if in.PermanentAddress != nil {
+		permanent, err = normalizeAddress(*in.PermanentAddress)
+		if err != nil {
+			return result, err
+		}
+	}
+	result = domain.BorrowerProfile{
+		UserID:           in.UserID,
+		DateOfBirth:      dob,
+		Gender:           in.Gender,
+		Occupation:       in.Occupation,
+		CurrentAddress:   current,
+		PermanentAddress: permanent,
+	}
+	if employer != "" {
+		result.Employer = &employer
+	}
+	err = s.bpr.Upsert(context.Background(), &result)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// normalizeAddress spells the state and country the same way for every profile and checks the pincode lies in the state
+func normalizeAddress(addr domain.Address) (result domain.Address, err error) {
+	if !addr.MatchesPincode() {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_ADDRESS, Message: domain.MessageINVALIDADDRESS}
+	}
+	result = addr
+	result.State, _ = domain.IndianState(addr.State)
+	result.Country = "India"
+	return result, nil
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+	"github.com/weCredit/internal/pkg/ratelimit"
+	"github.com/weCredit/internal/pkg/security"
+	"github.com/weCredit/internal/pkg/util"
+)
+
+const (
+	// recoveryCodeCount is the number of recovery codes a user gets
+	recoveryCodeCount = 10
+	// recoveryCodeLength is the number of characters of a recovery code, without the separator
+	recoveryCodeLength = 8
+	// recoveryCodeAlphabet leaves out characters that are easily confused when read back
+	recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+)
+
+type MfaService struct {
+	au  util.AppUtil
+	cfg config.WeCreditConfig
+	oh  security.OtpHasher
+	rcr domain.RecoveryCodeRepository
+	rl  ratelimit.Limiter
+	tm  security.TotpManager
+	tr  domain.Transactioner
+	usr domain.UserRepository
+}
+
+func NewMfaService(au util.AppUtil, cfg config.WeCreditConfig, oh security.OtpHasher, rcr domain.RecoveryCodeRepository, rl ratelimit.Limiter, tm security.TotpManager, tr domain.Transactioner, usr domain.UserRepository) domain.MfaService {
+	return &MfaService{
+		au:  au,
+		cfg: cfg,
+		oh:  oh,
+		rcr: rcr,
+		rl:  rl,
+		tm:  tm,
+		tr:  tr,
+		usr: usr,
+	}
+}
+
+// EnrollTotp implements domain.MfaService.
+func (s *MfaService) EnrollTotp(userID uuid.UUID) (result domain.TotpEnrollmentOutput, err error) {
+	usr, err := s.usr.FindByID(context.Background(), userID)
+	if err != nil {
+		return result, err
+	}
+	if usr.TotpEnabledAt != nil {
+		return result, domain.UserError{Code: domain.ErrorCodeTOTP_ALREADY_ENABLED, Message: domain.MessageTOTPALREADYENABLED}
+	}
+	secret, err := s.tm.GenerateSecret()
+	if err != nil {
+		return result, err
+	}
+	encrypted, err := s.tm.Encrypt(secret)
+	if err != nil {
+		return result, err
+	}
+	// The secret stays pending, replacing any earlier enrollment, until a code confirms the app has it
+	err = s.usr.UpdateTotp(context.Background(), usr.ID, &encrypted, nil)
+	if err != nil {
+		return result, err
+	}
+	return domain.TotpEnrollmentOutput{
+		Secret:          secret,
+		ProvisioningURI: s.tm.ProvisioningURI(usr.UserName, secret),
+	}, nil
+}
+
+// ConfirmTotp implements domain.MfaService.
+func (s *MfaService) ConfirmTotp(in domain.MfaCodeInput) (result domain.RecoveryCodesOutput, err error) {
+	usr, err := s.usr.FindByID(context.Background(), in.ID)
+	if err != nil {
+		return result, err
+	}
+	if usr.TotpEnabledAt != nil {
+		return result, domain.UserError{Code: domain.ErrorCodeTOTP_ALREADY_ENABLED, Message: domain.MessageTOTPALREADYENABLED}
+	}
+	if usr.TotpSecret == nil {
+		return result, domain.UserError{Code: domain.ErrorCodeTOTP_NOT_ENABLED, Message: domain.MessageTOTPNOTENABLED}
+	}
+	// Only the app can confirm the enrollment, there are no recovery codes yet
+	if in.TotpCode == "" {
+		return result, domain.UserError{Code: domain.ErrorCodeMFA_REQUIRED, Message: domain.MessageMFAREQUIRED}
+	}
+	err = s.checkAttempts(usr.ID)
+	if err != nil {
+		return result, err
+	}
+	step, err := s.validateTotp(usr, in.TotpCode)
+	if err != nil {
+		return result, err
+	}
+
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+
+	now := time.Now()
+	err = s.usr.UpdateTotp(ctx, usr.ID, usr.TotpSecret, &now)
+	if err != nil {
+		return result, err
+	}
+	err = s.usr.UpdateTotpLastStep(ctx, usr.ID, step)
+	if err != nil {
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return result, domain.UserError{Code: domain.ErrorCodeINVALID_MFA_CODE, Message: domain.MessageINVALIDMFACODE}
+		}
+		return result, err
+	}
+	result, err = s.replaceRecoveryCodes(ctx, usr.ID)
+	if err != nil {
+		return result, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// DisableTotp implements domain.MfaService.
+func (s *MfaService) DisableTotp(in domain.MfaCodeInput) (err error) {
+	usr, err := s.usr.FindByID(context.Background(), in.ID)
+	if err != nil {
+		return err
+	}
+	err = s.VerifySecondFactor(usr, in.TotpCode, in.RecoveryCode)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+
+	err = s.usr.UpdateTotp(ctx, usr.ID, nil, nil)
+	if err != nil {
+		return err
+	}
+	err = s.rcr.DeleteByUserID(ctx, usr.ID)
+	if err != nil {
+		return err
+	}
+
+	return s.tr.Commit(ctx)
+}
+
+// RegenerateRecoveryCodes implements domain.MfaService.
+func (s *MfaService) RegenerateRecoveryCodes(in domain.MfaCodeInput) (result domain.RecoveryCodesOutput, err error) {
+	usr, err := s.usr.FindByID(context.Background(), in.ID)
+	if err != nil {
+		return result, err
+	}
+	err = s.VerifySecondFactor(usr, in.TotpCode, in.RecoveryCode)
+	if err != nil {
+		return result, err
+	}
+
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+
+	result, err = s.replaceRecoveryCodes(ctx, usr.ID)
+	if err != nil {
+		return result, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// VerifySecondFactor implements domain.MfaService.
+func (s *MfaService) VerifySecondFactor(usr domain.User, totpCode, recoveryCode string) (err error) {
+	if usr.TotpEnabledAt == nil || usr.TotpSecret == nil {
+		return domain.UserError{Code: domain.ErrorCodeTOTP_NOT_ENABLED, Message: domain.MessageTOTPNOTENABLED}
+	}
+	if totpCode == "" && recoveryCode == "" {
+		return domain.UserError{Code: domain.ErrorCodeMFA_REQUIRED, Message: domain.MessageMFAREQUIRED}
+	}
+	// A six digit code is easy to guess without a limit
+	err = s.checkAttempts(usr.ID)
+	if err != nil {
+		return err
+	}
+
+	if totpCode != "" {
+		step, err := s.validateTotp(usr, totpCode)
+		if err != nil {
+			return err
+		}
+		// Every code is only accepted once, even within its validity window
+		err = s.usr.UpdateTotpLastStep(context.Background(), usr.ID, step)
+		if err != nil {
+			if errors.Is(err, domain.DataNotFoundError{}) {
+				return domain.UserError{Code: domain.ErrorCodeINVALID_MFA_CODE, Message: domain.MessageINVALIDMFACODE}
+			}
+			return err
+		}
+		return nil
+	}
+
+	err = s.rcr.Use(context.Background(), usr.ID, s.oh.Hash(normalizeRecoveryCode(recoveryCode)))
+	if err != nil {
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return domain.UserError{Code: domain.ErrorCodeINVALID_MFA_CODE, Message: domain.MessageINVALIDMFACODE}
+		}
+		return err
+	}
+	return nil
+}
+
+// validateTotp checks the code against the totp secret of the user and returns the time step it matched
+func (s *MfaService) validateTotp(usr domain.User, code string) (step int64, err error) {
+	secret, err := s.tm.Decrypt(*usr.TotpSecret)
+	if err != nil {
+		return 0, err
+	}
+	step, ok := s.tm.Validate(secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return 0, domain.UserError{Code: domain.ErrorCodeINVALID_MFA_CODE, Message: domain.MessageINVALIDMFACODE}
+	}
+	return step, nil
+}
+
+// checkAttempts limits the second factor attempts of the user to the otp attempts per MFA attempt window.
+// The configuration guarantees both are positive, so the limit always applies.
+func (s *MfaService) checkAttempts(userID uuid.UUID) (err error) {
+	allowed, retryAfter, err := s.rl.Allow(context.Background(), "mfa:"+userID.String(), s.cfg.OtpMaxAttempts, time.Duration(s.cfg.MfaAttemptWindow)*time.Second)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return domain.TooManyRequestsError{
+			Code:       domain.ErrorCodeRATE_LIMITED,
+			Message:    domain.MessageRATELIMITED,
+			RetryAfter: int64(math.Ceil(retryAfter.Seconds())),
+		}
+	}
+	return nil
+}
+
+// replaceRecoveryCodes deletes the recovery codes of the user and stores new ones, only their hashes are kept
+func (s *MfaService) replaceRecoveryCodes(ctx context.Context, userID uuid.UUID) (result domain.RecoveryCodesOutput, err error) {
+	err = s.rcr.DeleteByUserID(ctx, userID)
+	if err != nil {
+		return result, err
+	}
+	result.RecoveryCodes = make([]string, 0, recoveryCodeCount)
+	for len(result.RecoveryCodes) < recoveryCodeCount {
+		code := s.au.GenerateCode(recoveryCodeLength, recoveryCodeAlphabet)
+		entity := domain.RecoveryCode{
+			UserID:   userID,
+			CodeHash: s.oh.Hash(code),
+		}
+		err = s.rcr.Create(ctx, &entity)
+		if err != nil {
+			return result, err
+		}
+		result.RecoveryCodes = append(result.RecoveryCodes, code[:recoveryCodeLength/2]+"-"+code[recoveryCodeLength/2:])
+	}
+	return result, nil
+}
+
+// normalizeRecoveryCode removes the separator and spaces a user may type and lowercases the code
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+	"github.com/weCredit/internal/pkg/ratelimit"
+	"github.com/weCredit/internal/pkg/security"
+)
+
+// fakeRecoveryCodeRepository knows no recovery code, so every code is wrong
+type fakeRecoveryCodeRepository struct {
+	domain.RecoveryCodeRepository
+}
+
+func (r fakeRecoveryCodeRepository) Use(ctx context.Context, userID uuid.UUID, codeHash string) (err error) {
+	return domain.DataNotFoundError{}
+}
+
+func TestVerifySecondFactorLimitsAttempts(t *testing.T) {
+	cfg := config.WeCreditConfig{OtpHashSecret: "test-secret", OtpMaxAttempts: 3, MfaAttemptWindow: 60}
+	oh, err := security.NewOtpHasher(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &MfaService{cfg: cfg, oh: oh, rcr: fakeRecoveryCodeRepository{}, rl: ratelimit.NewMemoryLimiter()}
+
+	secret, enabledAt := "encrypted", time.Now()
+	usr := domain.User{Base: domain.Base{ID: uuid.Must(uuid.NewV4())}, TotpSecret: &secret, TotpEnabledAt: &enabledAt}
+	for attempt := 1; attempt <= 3; attempt++ {
+		err = s.VerifySecondFactor(usr, "", "wrong-code")
+		var usrErr domain.UserError
+		if !errors.As(err, &usrErr) || usrErr.Code != domain.ErrorCodeINVALID_MFA_CODE {
+			t.Fatalf("attempt %d: got %v, want INVALID_MFA_CODE", attempt, err)
+		}
+	}
+	err = s.VerifySecondFactor(usr, "", "wrong-code")
+	var tmrErr domain.TooManyRequestsError
+	if !errors.As(err, &tmrErr) || tmrErr.Code != domain.ErrorCodeRATE_LIMITED {
+		t.Fatalf("got %v, want RATE_LIMITED once the attempts ran out", err)
+	}
+	if tmrErr.RetryAfter <= 0 || tmrErr.RetryAfter > 60 {
+		t.Errorf("got a retry after of %d seconds, want within the 60 second window", tmrErr.RetryAfter)
+	}
+}
+
+func TestCheckAttemptsCountsPerUser(t *testing.T) {
+	s := &MfaService{cfg: config.WeCreditConfig{OtpMaxAttempts: 1, MfaAttemptWindow: 60}, rl: ratelimit.NewMemoryLimiter()}
+	userID, otherID := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	if err := s.checkAttempts(userID); err != nil {
+		t.Fatalf("first attempt: got %v, want no error", err)
+	}
+	var tmrErr domain.TooManyRequestsError
+	if err := s.checkAttempts(userID); !errors.As(err, &tmrErr) {
+		t.Errorf("second attempt: got %v, want RATE_LIMITED", err)
+	}
+	if err := s.checkAttempts(otherID); err != nil {
+		t.Errorf("another user: got %v, want no error", err)
+	}
+}
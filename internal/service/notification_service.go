@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/metrics"
+	"github.com/weCredit/internal/pkg/notification"
+	"github.com/weCredit/internal/pkg/phone"
+)
+
+type NotificationService struct {
+	dr  metrics.DeliveryRecorder
+	lcr domain.LoginCodeRepository
+}
+
+func NewNotificationService(dr metrics.DeliveryRecorder, lcr domain.LoginCodeRepository) domain.NotificationService {
+	return &NotificationService{
+		dr:  dr,
+		lcr: lcr,
+	}
+}
+
+// UpdateDeliveryStatus implements domain.NotificationService.
+func (s *NotificationService) UpdateDeliveryStatus(in domain.DeliveryStatusInput) (err error) {
+	status := notification.DeliveryStatusOf(in.ProviderStatus)
+	loginCode, err := s.lcr.FindByMessageID(context.Background(), in.MessageID)
+	if err != nil {
+		// A resent code replaces the message id of the previous one, the report still counts for the metrics
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			slog.Warn("no login code for the delivery report, only recording the metrics", "provider", in.Provider, "message_id", in.MessageID, "status", in.ProviderStatus)
+			if status.IsFinal() && in.To != "" {
+				s.dr.RecordDeliveryStatus(phone.CarrierPrefix(in.To), status)
+			}
+			return nil
+		}
+		return err
+	}
+
+	var receipt domain.DeliveryReceipt
+	if loginCode.ResponseMeta != nil {
+		if err = json.Unmarshal([]byte(*loginCode.ResponseMeta), &receipt); err != nil {
+			return err
+		}
+	}
+	// Reports may arrive out of order, so a final status is never replaced
+	if receipt.Status.IsFinal() {
+		return nil
+	}
+	receipt.Status = status
+	receipt.ProviderStatus = in.ProviderStatus
+	if in.ErrorCode != "" {
+		receipt.Error = in.ErrorCode
+	}
+	receipt.UpdatedAt = time.Now()
+
+	meta, err := json.Marshal(receipt)
+	if err != nil {
+		return err
+	}
+	err = s.lcr.UpdateDeliveryReceipt(context.Background(), loginCode.ID, loginCode.MessageID, string(meta))
+	if err != nil {
+		return err
+	}
+
+	if status.IsFinal() && receipt.Channel != domain.NotificationChannelEMAIL {
+		to := in.To
+		if to == "" {
+			to = loginCode.Username
+		}
+		s.dr.RecordDeliveryStatus(phone.CarrierPrefix(to), status)
+	}
+	return nil
+}
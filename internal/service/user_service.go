@@ -2,14 +2,23 @@ package service
 
 import (
 	"context"
+	"crypto/subtle"
+	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gofrs/uuid/v5"
 
 	"github.com/weCredit/internal/domain"
 	"github.com/weCredit/internal/pkg/config"
+	"github.com/weCredit/internal/pkg/notification"
+	"github.com/weCredit/internal/pkg/phone"
+	"github.com/weCredit/internal/pkg/ratelimit"
 	"github.com/weCredit/internal/pkg/security"
 	"github.com/weCredit/internal/pkg/util"
 )
@@ -18,18 +27,40 @@ type UserService struct {
 	au  util.AppUtil
 	cfg config.WeCreditConfig
 	lcr domain.LoginCodeRepository
+	ler domain.LoginEventRepository
+	mlr domain.MagicLinkRepository
+	mls security.MagicLinkSigner
+	ms  domain.MfaService
+	ns  notification.Sender
+	oh  security.OtpHasher
+	pcr domain.PhoneChangeRepository
+	rl  ratelimit.Limiter
+	rs  security.RevocationStore
 	scm security.Manager
+	ssr domain.SessionRepository
 	tr  domain.Transactioner
+	udr domain.UserDeviceRepository
 	usr domain.UserRepository
 }
 
-func NewUserService(au util.AppUtil, cfg config.WeCreditConfig, lcr domain.LoginCodeRepository, scm security.Manager, tr domain.Transactioner, usr domain.UserRepository) domain.UserService {
+func NewUserService(au util.AppUtil, cfg config.WeCreditConfig, lcr domain.LoginCodeRepository, ler domain.LoginEventRepository, mlr domain.MagicLinkRepository, mls security.MagicLinkSigner, ms domain.MfaService, ns notification.Sender, oh security.OtpHasher, pcr domain.PhoneChangeRepository, rl ratelimit.Limiter, rs security.RevocationStore, scm security.Manager, ssr domain.SessionRepository, tr domain.Transactioner, udr domain.UserDeviceRepository, usr domain.UserRepository) domain.UserService {
 	return &UserService{
 		au:  au,
 		cfg: cfg,
 		lcr: lcr,
+		ler: ler,
+		mlr: mlr,
+		mls: mls,
+		ms:  ms,
+		ns:  ns,
+		oh:  oh,
+		pcr: pcr,
+		rl:  rl,
+		rs:  rs,
 		scm: scm,
+		ssr: ssr,
 		tr:  tr,
+		udr: udr,
 		usr: usr,
 	}
 }
@@ -41,7 +72,79 @@ func (s *UserService) FindByUserName(username string) (result domain.User, err e
 
 // Login implements domain.UserService.
 func (s *UserService) Login(in domain.LoginInput) (result domain.LoginOutput, err error) {
-	usr, err := s.usr.FindByUserName(context.Background(), in.UserName)
+	var usr domain.User
+	defer func() {
+		s.recordLoginEvent(usr, in.UserName, "", domain.LoginEventOutcomeSUCCESS, err, in.ClientInfo)
+	}()
+	usr, err = s.usr.FindByUserName(context.Background(), in.UserName)
+	if err != nil {
+		// An unknown username fails like wrong credentials
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return result, domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+		}
+		return result, err
+	}
+	loginCode, err := s.verifyCode(usr.UserName, domain.LoginCodePurposeLOGIN, in.Otp)
+	if err != nil {
+		return result, err
+	}
+	return s.startSession(usr, in, func(ctx context.Context) error {
+		return s.consumeCode(ctx, loginCode)
+	})
+}
+
+// LoginWithMagicLink implements domain.UserService.
+func (s *UserService) LoginWithMagicLink(in domain.MagicLinkLoginInput) (result domain.LoginOutput, err error) {
+	var (
+		usr  domain.User
+		link domain.MagicLink
+	)
+	defer func() {
+		s.recordLoginEvent(usr, usr.UserName, link.Channel, domain.LoginEventOutcomeSUCCESS, err, in.ClientInfo)
+	}()
+	link, err = s.findMagicLink(in)
+	if err != nil {
+		return result, err
+	}
+	usr, err = s.usr.FindByID(context.Background(), link.UserID)
+	if err != nil {
+		return result, err
+	}
+	loginIn := domain.LoginInput{
+		UserName:     usr.UserName,
+		TotpCode:     in.TotpCode,
+		RecoveryCode: in.RecoveryCode,
+		Device:       in.Device,
+		DeviceID:     in.DeviceID,
+		ClientInfo:   in.ClientInfo,
+	}
+	// The link is only spent once the second factor passed, so the user can retry with a code
+	return s.startSession(usr, loginIn, func(ctx context.Context) error {
+		err := s.mlr.MarkUsed(ctx, link.ID)
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return domain.UserError{Code: domain.ErrorCodeINVALID_MAGIC_LINK, Message: domain.MessageINVALIDMAGICLINK}
+		}
+		return err
+	})
+}
+
+// startSession completes a login whose first factor was verified: it checks the second factor, runs consume to spend
+// the first factor and starts a new session, all in one transaction.
+func (s *UserService) startSession(usr domain.User, in domain.LoginInput, consume func(ctx context.Context) error) (result domain.LoginOutput, err error) {
+	err = checkUserStatus(usr)
+	if err != nil {
+		return result, err
+	}
+	// Once enrolled, the first factor alone is not enough. Staff who have not enrolled yet get a token without
+	// the mfa claim, which only lets them manage their own account until they enroll.
+	mfa := usr.TotpEnabledAt != nil
+	if mfa {
+		err = s.ms.VerifySecondFactor(usr, in.TotpCode, in.RecoveryCode)
+		if err != nil {
+			return result, err
+		}
+	}
+
 	ctx := context.Background()
 	ctx, err = s.tr.Begin(ctx)
 	if err != nil {
@@ -51,48 +154,405 @@ func (s *UserService) Login(in domain.LoginInput) (result domain.LoginOutput, er
 		s.tr.Rollback(ctx, err)
 	}()
 
+	err = consume(ctx)
 	if err != nil {
-		return result, errors.New("use not found please register first ")
+		return result, err
 	}
-	loginCode, err := s.lcr.FindByUsername(context.TODO(), usr.UserName)
+	familyID, err := uuid.NewV4()
 	if err != nil {
-		return result, errors.New("login code not found init login first ")
+		return result, err
 	}
-	if loginCode.Code != in.Otp {
-		return result, errors.New("invalid otp")
+	device, newDevice, err := s.registerDevice(ctx, usr.ID, in)
+	if err != nil {
+		return result, err
 	}
-	// Check if the OTP has expired
-	if time.Now().After(loginCode.ExpiryTime) {
-		return result, errors.New("otp has expired")
+	authTime := time.Now()
+	refreshToken, err := s.createSession(ctx, usr.ID, familyID, &device.ID, in.Device, mfa, &authTime, in.ClientInfo)
+	if err != nil {
+		return result, err
 	}
 	// generate token
 	ti := security.TokenMetadata{
-		UserID: usr.ID.String(),
-		Role:   usr.Role,
+		UserID:    usr.ID.String(),
+		Role:      usr.Role,
+		SessionID: familyID.String(),
+		Mfa:       mfa,
+		AuthTime:  authTime.Unix(),
 	}
 	token, err := s.scm.GenerateAuthToken(ti)
 	if err != nil {
 		return result, err
 
 	}
-	err = s.lcr.DeleteByUsername(ctx, in.UserName)
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	if newDevice {
+		s.sendNewDeviceAlert(usr, device)
+	}
+
+	return s.loginOutput(token, refreshToken), nil
+
+}
+
+// RefreshToken implements domain.UserService.
+func (s *UserService) RefreshToken(in domain.RefreshTokenInput) (result domain.LoginOutput, err error) {
+	ses, err := s.ssr.FindByRefreshTokenHash(context.Background(), security.HashToken(in.RefreshToken))
+	if err != nil {
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return result, domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+		}
+		return result, err
+	}
+	if ses.RevokedAt != nil || time.Now().After(ses.ExpiresAt) {
+		return result, domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+	}
+	// A rotated token is being used again, so it has leaked: revoke the whole family
+	if ses.RotatedAt != nil {
+		return result, s.revokeFamily(ses)
+	}
+	usr, err := s.usr.FindByID(context.Background(), ses.UserID)
+	if err != nil {
+		return result, err
+	}
+	err = checkUserStatus(usr)
+	if err != nil {
+		return result, err
+	}
+
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+
+	err = s.ssr.MarkRotated(ctx, ses.ID)
+	if err != nil {
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return result, s.revokeFamily(ses)
+		}
+		return result, err
+	}
+	device := ""
+	if ses.Device != nil {
+		device = *ses.Device
+	}
+	// The session keeps the second factor and the authentication time of the login it started with
+	refreshToken, err := s.createSession(ctx, usr.ID, ses.FamilyID, ses.UserDeviceID, device, ses.Mfa, ses.AuthTime, in.ClientInfo)
+	if err != nil {
+		return result, err
+	}
+	var authTime int64
+	if ses.AuthTime != nil {
+		authTime = ses.AuthTime.Unix()
+	}
+	token, err := s.scm.GenerateAuthToken(security.TokenMetadata{
+		UserID:    usr.ID.String(),
+		Role:      usr.Role,
+		SessionID: ses.FamilyID.String(),
+		Mfa:       ses.Mfa,
+		AuthTime:  authTime,
+	})
 	if err != nil {
-		log.Println("Failed to delete login code:", err)
+		return result, err
 	}
 	err = s.tr.Commit(ctx)
 	if err != nil {
 		return result, err
 	}
 
+	return s.loginOutput(token, refreshToken), nil
+}
+
+// Logout implements domain.UserService.
+func (s *UserService) Logout(in domain.LogoutInput) (err error) {
+	err = s.rs.RevokeToken(context.Background(), security.TokenClaims{
+		TokenMetadata: security.TokenMetadata{UserID: in.UserID.String()},
+		TokenID:       in.TokenID,
+		ExpiresAt:     in.ExpiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	if in.SessionID.IsNil() {
+		return nil
+	}
+	return s.ssr.RevokeFamily(context.Background(), in.SessionID)
+}
+
+// LogoutAll implements domain.UserService.
+func (s *UserService) LogoutAll(userID uuid.UUID) (err error) {
+	validAfter, err := s.rs.RevokeAllForUser(context.Background(), userID, time.Now())
+	if err != nil {
+		return err
+	}
+	s.rs.CacheValidAfter(userID, validAfter)
+	return s.ssr.RevokeByUserID(context.Background(), userID)
+}
+
+// createSession stores a new session for the user and returns its refresh token
+func (s *UserService) createSession(ctx context.Context, userID, familyID uuid.UUID, userDeviceID *uuid.UUID, device string, mfa bool, authTime *time.Time, ci domain.ClientInfo) (refreshToken string, err error) {
+	refreshToken, err = security.NewRefreshToken()
+	if err != nil {
+		return "", err
+	}
+	ses := domain.Session{
+		UserID:           userID,
+		FamilyID:         familyID,
+		UserDeviceID:     userDeviceID,
+		RefreshTokenHash: security.HashToken(refreshToken),
+		Device:           optionalString(device),
+		IPAddress:        optionalString(ci.IPAddress),
+		UserAgent:        optionalString(ci.UserAgent),
+		Mfa:              mfa,
+		AuthTime:         authTime,
+		ExpiresAt:        time.Now().Add(time.Duration(s.cfg.RefreshTokenExpiryPeriod) * time.Hour),
+	}
+	err = s.ssr.Create(ctx, &ses)
+	if err != nil {
+		return "", err
+	}
+	return refreshToken, nil
+}
+
+// recordLoginEvent records the outcome of an authentication attempt, the given outcome when it succeeded.
+//
+// It runs outside of any transaction so failed attempts are recorded too. Failures are only logged.
+func (s *UserService) recordLoginEvent(usr domain.User, username string, channel domain.NotificationChannel, success domain.LoginEventOutcome, err error, ci domain.ClientInfo) {
+	event := domain.LoginEvent{
+		Username:  username,
+		Outcome:   success,
+		Channel:   optionalString(string(channel)),
+		IPAddress: optionalString(ci.IPAddress),
+		UserAgent: optionalString(ci.UserAgent),
+		RequestID: optionalString(ci.RequestID),
+	}
+	if !usr.ID.IsNil() {
+		event.UserID = &usr.ID
+	}
+	if err != nil {
+		event.Outcome = loginEventOutcome(err)
+	}
+	if err := s.ler.Create(context.Background(), &event); err != nil {
+		slog.Error("failed to record login event", "err", err)
+	}
+}
+
+// loginEventOutcome maps the error of a failed authentication attempt to its outcome
+func loginEventOutcome(err error) domain.LoginEventOutcome {
+	var usrErr domain.UserError
+	if errors.As(err, &usrErr) {
+		switch usrErr.Code {
+		case domain.ErrorCodeINVALID_OTP:
+			return domain.LoginEventOutcomeWRONG_OTP
+		case domain.ErrorCodeOTP_EXPIRED, domain.ErrorCodeMAGIC_LINK_EXPIRED:
+			return domain.LoginEventOutcomeEXPIRED
+		case domain.ErrorCodeINVALID_MAGIC_LINK:
+			return domain.LoginEventOutcomeINVALID_LINK
+		case domain.ErrorCodeMFA_REQUIRED:
+			return domain.LoginEventOutcomeMFA_REQUIRED
+		case domain.ErrorCodeINVALID_MFA_CODE:
+			return domain.LoginEventOutcomeWRONG_MFA_CODE
+		case domain.ErrorCodeACCOUNT_SUSPENDED, domain.ErrorCodeACCOUNT_DEACTIVATED:
+			return domain.LoginEventOutcomeINACTIVE
+		}
+	}
+	var tmrErr domain.TooManyRequestsError
+	if errors.As(err, &tmrErr) {
+		if tmrErr.Code == domain.ErrorCodeOTP_LOCKED {
+			return domain.LoginEventOutcomeLOCKED
+		}
+		return domain.LoginEventOutcomeRATE_LIMITED
+	}
+	return domain.LoginEventOutcomeFAILED
+}
+
+// FindLoginEvents implements domain.UserService.
+func (s *UserService) FindLoginEvents(filter domain.LoginEventFilter) (result []domain.LoginEvent, total int64, err error) {
+	return s.ler.FindAll(context.Background(), filter)
+}
+
+// registerDevice records the device of a login and reports whether the user has not logged in from it before.
+//
+// A revoked device that logs in again counts as new, so the user is alerted again.
+func (s *UserService) registerDevice(ctx context.Context, userID uuid.UUID, in domain.LoginInput) (result domain.UserDevice, newDevice bool, err error) {
+	now := time.Now()
+	fingerprint := security.HashToken(in.UserAgent)
+	result, err = s.udr.FindByFingerprint(ctx, userID, in.DeviceID, fingerprint)
+	if err != nil {
+		if !errors.Is(err, domain.DataNotFoundError{}) {
+			return result, false, err
+		}
+		result = domain.UserDevice{
+			UserID:        userID,
+			DeviceID:      in.DeviceID,
+			Fingerprint:   fingerprint,
+			Name:          optionalString(in.Device),
+			UserAgent:     optionalString(in.UserAgent),
+			LastIPAddress: optionalString(in.IPAddress),
+			LastSeenAt:    now,
+		}
+		err = s.udr.Create(ctx, &result)
+		return result, true, err
+	}
+
+	newDevice = result.RevokedAt != nil
+	if in.Device != "" {
+		result.Name = &in.Device
+	}
+	result.LastIPAddress = optionalString(in.IPAddress)
+	result.LastSeenAt = now
+	result.RevokedAt = nil
+	err = s.udr.Update(ctx, result.ID, &result)
+	return result, newDevice, err
+}
+
+// sendNewDeviceAlert tells the user about a login from a new device by SMS and, when verified, by email.
+//
+// Failures are only logged because the login already succeeded.
+func (s *UserService) sendNewDeviceAlert(usr domain.User, device domain.UserDevice) {
+	name := "an unknown device"
+	if device.Name != nil {
+		name = *device.Name
+	}
+	ip := "unknown"
+	if device.LastIPAddress != nil {
+		ip = *device.LastIPAddress
+	}
+	msg := domain.OtpMessage{
+		To:      usr.UserName,
+		Channel: domain.NotificationChannelSMS,
+		Subject: "New login to your weCredit account",
+		Body: fmt.Sprintf("New login to your weCredit account from %s (IP %s) at %s. If this was not you, revoke the device in the app and contact support immediately.",
+			name, ip, device.LastSeenAt.UTC().Format("02 Jan 2006 15:04 MST")),
+	}
+	if _, err := s.ns.Send(context.Background(), msg); err != nil {
+		slog.Error("failed to send new device alert", "user_id", usr.ID, "err", err)
+	}
+	if usr.Email == nil || usr.EmailVerifiedAt == nil {
+		return
+	}
+	msg.To = *usr.Email
+	msg.Channel = domain.NotificationChannelEMAIL
+	if _, err := s.ns.Send(context.Background(), msg); err != nil {
+		slog.Error("failed to send new device alert email", "user_id", usr.ID, "err", err)
+	}
+}
+
+// FindDevices implements domain.UserService.
+func (s *UserService) FindDevices(userID uuid.UUID) (result []domain.UserDevice, err error) {
+	return s.udr.FindAllByUserID(context.Background(), userID)
+}
+
+// RevokeDevice implements domain.UserService.
+func (s *UserService) RevokeDevice(in domain.RevokeDeviceInput) (err error) {
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+
+	err = s.udr.Revoke(ctx, in.ID, in.DeviceID)
+	if err != nil {
+		return err
+	}
+	// The refresh tokens of the device stop working, its access tokens run until they expire
+	err = s.ssr.RevokeByUserDeviceID(ctx, in.DeviceID)
+	if err != nil {
+		return err
+	}
+
+	return s.tr.Commit(ctx)
+}
+
+// revokeFamily revokes every session that shares the family of the reused session.
+//
+// It runs outside of any transaction so the revocation is persisted even though the refresh fails.
+func (s *UserService) revokeFamily(ses domain.Session) (err error) {
+	slog.Warn("refresh token reuse detected, revoking the session family", "user_id", ses.UserID, "family_id", ses.FamilyID)
+	err = s.ssr.RevokeFamily(context.Background(), ses.FamilyID)
+	if err != nil {
+		return err
+	}
+	return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+}
+
+// loginOutput builds the login output for the issued tokens
+func (s *UserService) loginOutput(token, refreshToken string) domain.LoginOutput {
 	return domain.LoginOutput{
-		Token:     token,
-		ExpiresIn: int64(s.cfg.AuthExpiryPeriod),
-	}, nil
+		Token:            token,
+		ExpiresIn:        int64(s.cfg.AuthExpiryPeriod),
+		RefreshToken:     refreshToken,
+		RefreshExpiresIn: int64((time.Duration(s.cfg.RefreshTokenExpiryPeriod) * time.Hour).Seconds()),
+	}
+}
 
+// optionalString returns nil for an empty string
+func optionalString(v string) *string {
+	if v == "" {
+		return nil
+	}
+	return &v
+}
+
+// recordFailedAttempt counts a wrong otp and locks the username once the limit is reached.
+//
+// It runs outside of any transaction so the attempt is persisted even though the login fails. It always returns
+// an error, as the otp was wrong.
+func (s *UserService) recordFailedAttempt(loginCode domain.LoginCode) (err error) {
+	attempts, err := s.lcr.IncrementAttempts(context.Background(), loginCode.ID)
+	if err != nil {
+		return err
+	}
+	if attempts < s.cfg.OtpMaxAttempts {
+		return domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageINVALIDOTP}
+	}
+
+	lockout := time.Duration(s.cfg.OtpLockoutPeriod) * time.Minute
+	lockedUntil := time.Now().Add(lockout)
+	loginCode.Attempts = attempts
+	loginCode.Status = domain.LoginCodeStatusFAILED
+	loginCode.LockedUntil = &lockedUntil
+	err = s.lcr.Update(context.Background(), loginCode.ID, &loginCode)
+	if err != nil {
+		return err
+	}
+	// The code is now failed, so this attempt is refused whatever the lockout period
+	return domain.TooManyRequestsError{
+		Code:       domain.ErrorCodeOTP_LOCKED,
+		Message:    domain.MessageOTPLOCKED,
+		RetryAfter: int64(math.Ceil(lockout.Seconds())),
+	}
+}
+
+// checkLoginCodeLock returns an error when the login code is locked out at the given time
+func checkLoginCodeLock(loginCode domain.LoginCode, now time.Time) error {
+	if loginCode.LockedUntil == nil || !now.Before(*loginCode.LockedUntil) {
+		return nil
+	}
+	return domain.TooManyRequestsError{
+		Code:       domain.ErrorCodeOTP_LOCKED,
+		Message:    domain.MessageOTPLOCKED,
+		RetryAfter: int64(math.Ceil(loginCode.LockedUntil.Sub(now).Seconds())),
+	}
 }
 
 // RegisterUser implements domain.UserService.
 func (s *UserService) RegisterUser(in domain.RegisterUserInput) (result domain.User, err error) {
+	// Privileged roles can only be granted by an admin
+	if in.Role == "" {
+		in.Role = domain.UserRoleUSER
+	}
+	if in.Role != domain.UserRoleUSER {
+		return result, domain.ForbiddenAccessError{Code: domain.ErrorCodeFORBIDDEN_ACCESS, Message: domain.MessageFORBIDDENACCESS}
+	}
 	result = domain.User{
 		UserName: in.UserName,
 		FullName: in.FullName,
@@ -105,57 +565,907 @@ func (s *UserService) RegisterUser(in domain.RegisterUserInput) (result domain.U
 	return result, err
 }
 
-func (s *UserService) InitLogin(in domain.InitLoginInput) (err error) {
-	usr, err := s.usr.FindByUserName(context.Background(), in.UserName)
+func (s *UserService) InitLogin(in domain.InitLoginInput) (result domain.InitLoginOutput, err error) {
+	if in.Channel == "" {
+		in.Channel = domain.NotificationChannelSMS
+	}
+	sent := domain.LoginEventOutcomeOTP_SENT
+	if in.Mode == domain.LoginModeMAGIC_LINK {
+		if s.cfg.MagicLinkURL == "" {
+			return result, domain.UserError{Code: domain.ErrorCodeLOGIN_MODE_DISABLED, Message: domain.MessageLOGINMODEDISABLED}
+		}
+		sent = domain.LoginEventOutcomeLINK_SENT
+	}
+	var usr domain.User
+	defer func() {
+		s.recordLoginEvent(usr, in.UserName, in.Channel, sent, err, in.ClientInfo)
+	}()
+	// Every call may send a paid SMS, so throttle before doing anything else
+	err = s.checkInitLoginLimits(in)
 	if err != nil {
-		return err
+		return result, err
 	}
-	if usr.ID.IsNil() {
-		return errors.New("user not exists")
+	usr, err = s.usr.FindByUserName(context.Background(), in.UserName)
+	if err != nil {
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return result, domain.NotFoundError{}
+		}
+		return result, err
 	}
-	loginCode, err := s.lcr.FindByUsername(context.TODO(), usr.UserName)
-	if err != nil && !errors.Is(err, domain.DataNotFoundError{}) {
-		return err
+	err = checkUserStatus(usr)
+	if err != nil {
+		return result, err
+	}
+	msg, err := otpMessageFor(usr, in.Channel)
+	if err != nil {
+		return result, err
+	}
+	if in.Mode == domain.LoginModeMAGIC_LINK {
+		return s.issueMagicLink(usr, msg, in.ClientInfo)
+	}
+
+	return s.issueCode(usr.UserName, domain.LoginCodePurposeLOGIN, nil, msg)
+}
 
+// otpMessageFor returns a message to the user on the channel. Only a verified email can receive a code.
+func otpMessageFor(usr domain.User, channel domain.NotificationChannel) (result domain.OtpMessage, err error) {
+	result = domain.OtpMessage{
+		To:      usr.UserName,
+		Channel: channel,
 	}
-	var otp string
-	if loginCode.Code != "" {
-		otp = s.au.GenerateOTP(6)
-		id := loginCode.ID
-		loginCode := domain.LoginCode{
-			Code:       otp,
-			Status:     domain.LoginCodeStatusPENDING,
-			Username:   usr.UserName,
-			ExpiryTime: time.Now().Add(5 * time.Minute),
+	if channel == domain.NotificationChannelEMAIL {
+		if usr.Email == nil || usr.EmailVerifiedAt == nil {
+			return result, domain.UserError{Code: domain.ErrorCodeEMAIL_NOT_VERIFIED, Message: domain.MessageEMAILNOTVERIFIED}
 		}
-		err = s.lcr.Update(context.TODO(), id, &loginCode)
-		if err != nil {
-			return err
+		result.To = *usr.Email
+	}
+	return result, nil
+}
 
-		}
-	} else {
-		otp = s.au.GenerateOTP(6)
-		loginCode := domain.LoginCode{
-			Code:       otp,
-			Status:     domain.LoginCodeStatusPENDING,
-			Username:   usr.UserName,
-			ExpiryTime: time.Now().Add(5 * time.Minute),
-		}
-		err = s.lcr.Create(context.TODO(), &loginCode)
+// issueMagicLink stores a new magic link for the user, bound to the requesting client, and sends it.
+//
+// The token in the link is a random id signed with the magic link secret, so forged links are rejected without a lookup.
+// Earlier links of the user stop working.
+func (s *UserService) issueMagicLink(usr domain.User, msg domain.OtpMessage, ci domain.ClientInfo) (result domain.InitLoginOutput, err error) {
+	id := s.au.GenerateUniqueToken()
+	token := id + "." + s.mls.Sign(id)
+	binding := s.au.GenerateUniqueToken()
+	ttl := time.Duration(s.cfg.MagicLinkTTL) * time.Second
+
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
 
+	err = s.mlr.ExpireByUserID(ctx, usr.ID)
+	if err != nil {
+		return result, err
+	}
+	entity := domain.MagicLink{
+		UserID:      usr.ID,
+		TokenHash:   security.HashToken(token),
+		BindingHash: security.HashToken(binding),
+		Fingerprint: security.HashToken(ci.UserAgent),
+		Channel:     msg.Channel,
+		ExpiresAt:   time.Now().Add(ttl),
 	}
+	err = s.mlr.Create(ctx, &entity)
 	if err != nil {
-		return err
+		return result, err
 	}
-	sms := domain.OtpMessage{
-		To:  in.UserName,
-		Otp: otp,
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
 	}
-	err = s.au.SendOtp(s.cfg, sms)
-	return err
 
+	link := s.cfg.MagicLinkURL + "?token=" + url.QueryEscape(token)
+	msg.Subject = "Your weCredit login link"
+	msg.Body = fmt.Sprintf("Open this link to log in to weCredit: %s. It expires in %d minutes and only works in the browser or app that requested it. Do not share it with anyone.",
+		link, int(math.Ceil(ttl.Minutes())))
+	_, err = s.ns.Send(context.Background(), msg)
+	if err != nil {
+		return result, err
+	}
+
+	return domain.InitLoginOutput{
+		ExpiresIn:    int64(s.cfg.MagicLinkTTL),
+		BindingToken: binding,
+	}, nil
 }
 
-func (s *UserService) FindByID(id uuid.UUID) (result domain.User, err error) {
-	return s.usr.FindByID(context.Background(), id)
+// findMagicLink returns the unused and unexpired magic link of the token, if the client is the one that requested it
+func (s *UserService) findMagicLink(in domain.MagicLinkLoginInput) (result domain.MagicLink, err error) {
+	invalid := domain.UserError{Code: domain.ErrorCodeINVALID_MAGIC_LINK, Message: domain.MessageINVALIDMAGICLINK}
+	i := strings.LastIndex(in.Token, ".")
+	if i < 0 || !s.mls.Verify(in.Token[i+1:], in.Token[:i]) {
+		return result, invalid
+	}
+	result, err = s.mlr.FindByTokenHash(context.Background(), security.HashToken(in.Token))
+	if err != nil {
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return result, invalid
+		}
+		return result, err
+	}
+	if result.UsedAt != nil {
+		return result, invalid
+	}
+	// A link forwarded to, or intercepted on, another device does not come with the binding token
+	if subtle.ConstantTimeCompare([]byte(result.BindingHash), []byte(security.HashToken(in.BindingToken))) != 1 ||
+		subtle.ConstantTimeCompare([]byte(result.Fingerprint), []byte(security.HashToken(in.UserAgent))) != 1 {
+		return result, invalid
+	}
+	if time.Now().After(result.ExpiresAt) {
+		return result, domain.UserError{Code: domain.ErrorCodeMAGIC_LINK_EXPIRED, Message: domain.MessageMAGICLINKEXPIRED}
+	}
+	return result, nil
+}
+
+// issueCode stores a new code of the given purpose for the username and sends it.
+//
+// The pending code of the same purpose, if any, is replaced.
+func (s *UserService) issueCode(username string, purpose domain.LoginCodePurpose, target *string, msg domain.OtpMessage) (result domain.InitLoginOutput, err error) {
+	loginCode, err := s.lcr.FindByUsername(context.Background(), username, purpose)
+	if err != nil && !errors.Is(err, domain.DataNotFoundError{}) {
+		return result, err
+
+	}
+	now := time.Now()
+	// Do not hand out a new code while the username is locked out
+	if err = checkLoginCodeLock(loginCode, now); err != nil {
+		return result, err
+	}
+	if !loginCode.ID.IsNil() {
+		err = s.checkResendPolicy(loginCode, now)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	otp := s.au.GenerateCode(s.cfg.OtpLength, s.cfg.OtpAlphabet)
+	entity := domain.LoginCode{
+		Code:       s.oh.Hash(otp),
+		Status:     domain.LoginCodeStatusPENDING,
+		Purpose:    purpose,
+		Target:     target,
+		Username:   username,
+		ExpiryTime: now.Add(time.Duration(s.cfg.OtpTTL) * time.Second),
+		LastSentAt: &now,
+	}
+	if !loginCode.ID.IsNil() {
+		entity.ID = loginCode.ID
+		err = s.lcr.Update(context.Background(), loginCode.ID, &entity)
+	} else {
+		err = s.lcr.Create(context.Background(), &entity)
+	}
+	if err != nil {
+		return result, err
+	}
+	msg.Otp = otp
+	receipt, err := s.ns.Send(context.Background(), msg)
+	// Keep the receipt even when every provider failed, so support can tell what happened
+	s.saveDeliveryReceipt(entity.ID, receipt)
+	if err != nil {
+		return result, err
+	}
+
+	return domain.InitLoginOutput{
+		ExpiresIn:   int64(s.cfg.OtpTTL),
+		ResendAfter: int64(s.cfg.OtpResendCooldown),
+	}, nil
+}
+
+// saveDeliveryReceipt stores the delivery receipt on the login code.
+//
+// Failures are only logged because the otp may already be on its way.
+func (s *UserService) saveDeliveryReceipt(id uuid.UUID, receipt domain.DeliveryReceipt) {
+	if receipt.Status == "" {
+		return
+	}
+	meta, err := json.Marshal(receipt)
+	if err != nil {
+		slog.Error("failed to encode delivery receipt", "err", err)
+		return
+	}
+	err = s.lcr.UpdateDeliveryReceipt(context.Background(), id, optionalString(receipt.MessageID), string(meta))
+	if err != nil {
+		slog.Error("failed to save delivery receipt", "err", err)
+	}
+}
+
+// FindOtpDeliveries implements domain.UserService.
+func (s *UserService) FindOtpDeliveries(userID uuid.UUID) (result []domain.OtpDelivery, err error) {
+	usr, err := s.usr.FindByID(context.Background(), userID)
+	if err != nil {
+		return result, err
+	}
+	codes, err := s.lcr.FindAllByUsername(context.Background(), usr.UserName)
+	if err != nil {
+		return result, err
+	}
+	result = make([]domain.OtpDelivery, 0, len(codes))
+	for _, lc := range codes {
+		delivery := domain.OtpDelivery{
+			Purpose:    lc.Purpose,
+			Status:     lc.Status,
+			ExpiryTime: lc.ExpiryTime,
+			LastSentAt: lc.LastSentAt,
+			ClosedAt:   lc.DeletedAt,
+		}
+		if lc.ResponseMeta != nil {
+			var receipt domain.DeliveryReceipt
+			if err := json.Unmarshal([]byte(*lc.ResponseMeta), &receipt); err != nil {
+				slog.Error("failed to decode delivery receipt", "login_code_id", lc.ID, "err", err)
+			} else {
+				delivery.Receipt = &receipt
+			}
+		}
+		result = append(result, delivery)
+	}
+	return result, nil
+}
+
+// verifyCode checks the otp against the pending code of the given purpose for the username
+func (s *UserService) verifyCode(username string, purpose domain.LoginCodePurpose, otp string) (result domain.LoginCode, err error) {
+	loginCode, err := s.lcr.FindByUsername(context.Background(), username, purpose)
+	if err != nil {
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageINVALIDOTP}
+		}
+		return result, err
+	}
+	now := time.Now()
+	if err = checkLoginCodeLock(loginCode, now); err != nil {
+		return result, err
+	}
+	// A failed code can no longer be used even after the lockout is over
+	if loginCode.Status == domain.LoginCodeStatusFAILED {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageINVALIDOTP}
+	}
+	// Check if the OTP has expired
+	if now.After(loginCode.ExpiryTime) {
+		return result, domain.UserError{Code: domain.ErrorCodeOTP_EXPIRED, Message: domain.MessageOTPEXPIRED}
+	}
+	if !s.oh.Compare(loginCode.Code, otp) {
+		return result, s.recordFailedAttempt(loginCode)
+	}
+	return loginCode, nil
+}
+
+// consumeCode spends a verified code in the transaction of the context. When concurrent requests verified the same
+// code, only the first one gets to spend it.
+func (s *UserService) consumeCode(ctx context.Context, loginCode domain.LoginCode) (err error) {
+	err = s.lcr.Consume(ctx, loginCode.ID)
+	if errors.Is(err, domain.DataNotFoundError{}) {
+		return domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageINVALIDOTP}
+	}
+	return err
+}
+
+// checkResendPolicy enforces the cooldown between two codes and the maximum number of resends per hour
+func (s *UserService) checkResendPolicy(loginCode domain.LoginCode, now time.Time) (err error) {
+	if loginCode.LastSentAt != nil {
+		resendAt := loginCode.LastSentAt.Add(time.Duration(s.cfg.OtpResendCooldown) * time.Second)
+		if now.Before(resendAt) {
+			return domain.TooManyRequestsError{
+				Code:       domain.ErrorCodeOTP_RESEND_COOLDOWN,
+				Message:    domain.MessageOTPRESENDCOOLDOWN,
+				RetryAfter: int64(math.Ceil(resendAt.Sub(now).Seconds())),
+			}
+		}
+	}
+	if s.cfg.OtpMaxResendsPerHour <= 0 {
+		return nil
+	}
+	allowed, retryAfter, err := s.rl.Allow(context.Background(), "otp_resend:"+loginCode.Username, s.cfg.OtpMaxResendsPerHour, time.Hour)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return domain.TooManyRequestsError{
+			Code:       domain.ErrorCodeOTP_RESEND_LIMIT,
+			Message:    domain.MessageOTPRESENDLIMIT,
+			RetryAfter: int64(math.Ceil(retryAfter.Seconds())),
+		}
+	}
+	return nil
+}
+
+func (s *UserService) FindByID(id uuid.UUID) (result domain.User, err error) {
+	return s.usr.FindByID(context.Background(), id)
+}
+
+// FindAll implements domain.UserService.
+func (s *UserService) FindAll(filter domain.UserFilter) (result []domain.User, total int64, err error) {
+	return s.usr.FindAll(context.Background(), filter)
+}
+
+// UpdateProfile implements domain.UserService.
+func (s *UserService) UpdateProfile(in domain.UpdateUserInput) (result domain.User, err error) {
+	// Users cannot grant themselves a role, and the contact details are only changed once verified
+	if in.Role != nil {
+		return result, domain.ForbiddenAccessError{Code: domain.ErrorCodeFORBIDDEN_ACCESS, Message: domain.MessageNOT_ALLOWED_FOR_OPERATION}
+	}
+	if in.UserName != nil || in.Email != nil {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessagePROFILEFIELDREADONLY}
+	}
+	if in.FullName != nil {
+		fullName := strings.TrimSpace(*in.FullName)
+		if fullName == "" {
+			return result, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessageFULLNAMEREQUIRED}
+		}
+		err = s.usr.UpdateFullName(context.Background(), in.ID, fullName)
+		if err != nil {
+			return result, err
+		}
+	}
+	return s.usr.FindByID(context.Background(), in.ID)
+}
+
+// FindPreferences implements domain.UserService.
+func (s *UserService) FindPreferences(userID uuid.UUID) (result domain.UserPreferences, err error) {
+	usr, err := s.usr.FindByID(context.Background(), userID)
+	if err != nil {
+		return result, err
+	}
+	return domain.ParseStoredUserPreferences(usr.Preferences), nil
+}
+
+// UpdatePreferences implements domain.UserService.
+func (s *UserService) UpdatePreferences(in domain.UpdatePreferencesInput) (result domain.UserPreferences, err error) {
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+
+	prefs := in.Preferences
+	if in.Merge {
+		// The row stays locked until the commit, so concurrent patches apply one after the other
+		var stored domain.JSONB
+		stored, err = s.usr.FindPreferencesForUpdate(ctx, in.ID)
+		if err != nil {
+			return result, err
+		}
+		// The patch applies to the effective preferences, so removing a key resets it to its default
+		var current domain.JSONB
+		current, err = domain.ParseStoredUserPreferences(stored).JSONB()
+		if err != nil {
+			return result, err
+		}
+		prefs = current.MergePatch(in.Preferences)
+	}
+	result, err = domain.ParseUserPreferences(prefs)
+	if err != nil {
+		return result, err
+	}
+	// Only the known keys are stored, with every default filled in
+	prefs, err = result.JSONB()
+	if err != nil {
+		return result, err
+	}
+	err = s.usr.UpdatePreferences(ctx, in.ID, prefs)
+	if err != nil {
+		return result, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// UpdateRole implements domain.UserService.
+func (s *UserService) UpdateRole(in domain.UpdateUserRoleInput) (result domain.User, err error) {
+	// Admins cannot change their own role, so the last admin cannot lock everyone out
+	if in.ID == in.ActorID {
+		return result, domain.ForbiddenAccessError{Code: domain.ErrorCodeFORBIDDEN_ACCESS, Message: domain.MessageNOT_ALLOWED_FOR_OPERATION}
+	}
+	err = s.usr.UpdateRole(context.Background(), in.ID, in.Role)
+	if err != nil {
+		return result, err
+	}
+	// Tokens carry the role, so the old ones are revoked and clients pick up the new role on refresh
+	validAfter, err := s.rs.RevokeAllForUser(context.Background(), in.ID, time.Now())
+	if err != nil {
+		return result, err
+	}
+	s.rs.CacheValidAfter(in.ID, validAfter)
+	return s.usr.FindByID(context.Background(), in.ID)
+}
+
+// BootstrapAdmin implements domain.UserService.
+//
+// Roles are otherwise only granted by an admin, so this is how the first one is created. Once an admin exists it does nothing.
+func (s *UserService) BootstrapAdmin(username string) (err error) {
+	ctx := context.Background()
+	_, admins, err := s.usr.FindAll(ctx, domain.UserFilter{Role: domain.UserRoleADMIN, Page: 1, Size: 1})
+	if err != nil {
+		return err
+	}
+	if admins > 0 {
+		return nil
+	}
+	usr, err := s.usr.FindByUserName(ctx, username)
+	if errors.Is(err, domain.DataNotFoundError{}) {
+		usr = domain.User{UserName: username, FullName: "Administrator", Role: string(domain.UserRoleADMIN)}
+		err = s.usr.CreateUser(ctx, &usr)
+		if err != nil {
+			return err
+		}
+		slog.Info("created the first admin", "user_id", usr.ID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	// A suspended or deactivated account must not quietly become the only admin
+	if usr.Status != domain.UserStatusACTIVE {
+		return fmt.Errorf("BOOTSTRAP_ADMIN_USERNAME belongs to a user with the status %s, only an active user can become the first admin", usr.Status)
+	}
+	err = s.usr.UpdateRole(ctx, usr.ID, domain.UserRoleADMIN)
+	if err != nil {
+		return err
+	}
+	slog.Info("promoted the first admin", "user_id", usr.ID)
+	return nil
+}
+
+// ChangeStatus implements domain.UserService.
+//
+// Every status but ACTIVE revokes the tokens, sessions and magic links of the user. Restoring a deleted user fails
+// when another account registered its mobile number in the meantime.
+func (s *UserService) ChangeStatus(in domain.ChangeUserStatusInput) (result domain.User, err error) {
+	// Users may only deactivate their own account, and admins cannot lock themselves out
+	if in.ID == in.ActorID && in.Status != domain.UserStatusDEACTIVATED {
+		return result, domain.ForbiddenAccessError{Code: domain.ErrorCodeFORBIDDEN_ACCESS, Message: domain.MessageNOT_ALLOWED_FOR_OPERATION}
+	}
+	usr, err := s.usr.FindByIDIncludingDeleted(context.Background(), in.ID)
+	if err != nil {
+		return result, err
+	}
+	if !usr.Status.CanChangeTo(in.Status) {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_STATUS_CHANGE, Message: domain.MessageINVALIDSTATUSCHANGE}
+	}
+	if usr.Status == domain.UserStatusDELETED {
+		err = s.checkUserNameAvailable(usr.ID, usr.UserName)
+		if err != nil {
+			return result, err
+		}
+		if usr.Email != nil {
+			err = s.checkEmailAvailable(usr.ID, *usr.Email)
+			if err != nil {
+				return result, err
+			}
+		}
+	}
+
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+
+	err = s.usr.UpdateStatus(ctx, usr.ID, usr.Status, in.Status, optionalString(in.Reason))
+	if err != nil {
+		// The status changed since it was read
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return result, domain.UserError{Code: domain.ErrorCodeINVALID_STATUS_CHANGE, Message: domain.MessageINVALIDSTATUSCHANGE}
+		}
+		return result, err
+	}
+	var validAfter time.Time
+	if in.Status != domain.UserStatusACTIVE {
+		validAfter, err = s.rs.RevokeAllForUser(ctx, usr.ID, time.Now())
+		if err != nil {
+			return result, err
+		}
+		err = s.ssr.RevokeByUserID(ctx, usr.ID)
+		if err != nil {
+			return result, err
+		}
+		err = s.mlr.ExpireByUserID(ctx, usr.ID)
+		if err != nil {
+			return result, err
+		}
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	// A rolled back change must not revoke anything, so the cache only learns about it now
+	if !validAfter.IsZero() {
+		s.rs.CacheValidAfter(usr.ID, validAfter)
+	}
+
+	return s.usr.FindByIDIncludingDeleted(context.Background(), usr.ID)
+}
+
+// checkUserStatus returns the error a login of the user fails with when the account is not active.
+//
+// Deleted users are not found at all, so they cannot be told apart from unknown usernames.
+func checkUserStatus(usr domain.User) error {
+	switch usr.Status {
+	case domain.UserStatusSUSPENDED:
+		return domain.UserError{Code: domain.ErrorCodeACCOUNT_SUSPENDED, Message: domain.MessageACCOUNTSUSPENDED}
+	case domain.UserStatusDEACTIVATED:
+		return domain.UserError{Code: domain.ErrorCodeACCOUNT_DEACTIVATED, Message: domain.MessageACCOUNTDEACTIVATED}
+	case domain.UserStatusDELETED:
+		return domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+	}
+	return nil
+}
+
+// RequestEmailVerification implements domain.UserService.
+func (s *UserService) RequestEmailVerification(in domain.UpdateEmailInput) (result domain.InitLoginOutput, err error) {
+	email := strings.ToLower(strings.TrimSpace(in.Email))
+	err = s.checkEmailAvailable(in.ID, email)
+	if err != nil {
+		return result, err
+	}
+	usr, err := s.usr.FindByID(context.Background(), in.ID)
+	if err != nil {
+		return result, err
+	}
+	msg := domain.OtpMessage{
+		To:      email,
+		Channel: domain.NotificationChannelEMAIL,
+	}
+	return s.issueCode(usr.UserName, domain.LoginCodePurposeEMAIL_VERIFICATION, &email, msg)
+}
+
+// VerifyEmail implements domain.UserService.
+func (s *UserService) VerifyEmail(in domain.VerifyEmailInput) (result domain.User, err error) {
+	usr, err := s.usr.FindByID(context.Background(), in.ID)
+	if err != nil {
+		return result, err
+	}
+	loginCode, err := s.verifyCode(usr.UserName, domain.LoginCodePurposeEMAIL_VERIFICATION, in.Otp)
+	if err != nil {
+		return result, err
+	}
+	if loginCode.Target == nil {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageINVALIDOTP}
+	}
+	// Another account may have verified the same email since the code was sent
+	err = s.checkEmailAvailable(usr.ID, *loginCode.Target)
+	if err != nil {
+		return result, err
+	}
+
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+
+	err = s.usr.UpdateEmail(ctx, usr.ID, *loginCode.Target, time.Now())
+	if err != nil {
+		return result, err
+	}
+	err = s.consumeCode(ctx, loginCode)
+	if err != nil {
+		return result, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	return s.usr.FindByID(context.Background(), usr.ID)
+}
+
+// StepUpChallenge implements domain.UserService.
+func (s *UserService) StepUpChallenge(in domain.StepUpChallengeInput) (result domain.InitLoginOutput, err error) {
+	if in.Channel == "" {
+		in.Channel = domain.NotificationChannelSMS
+	}
+	usr, err := s.usr.FindByID(context.Background(), in.ID)
+	if err != nil {
+		return result, err
+	}
+	msg, err := otpMessageFor(usr, in.Channel)
+	if err != nil {
+		return result, err
+	}
+	return s.issueCode(usr.UserName, domain.LoginCodePurposeSTEP_UP, nil, msg)
+}
+
+// StepUpVerify implements domain.UserService.
+//
+// The new token keeps the session and second factor of the current one. The session records the authentication
+// time too, so tokens refreshed from it keep it.
+func (s *UserService) StepUpVerify(in domain.StepUpVerifyInput) (result domain.StepUpOutput, err error) {
+	usr, err := s.usr.FindByID(context.Background(), in.ID)
+	if err != nil {
+		return result, err
+	}
+	loginCode, err := s.verifyCode(usr.UserName, domain.LoginCodePurposeSTEP_UP, in.Otp)
+	if err != nil {
+		return result, err
+	}
+	// Like a login, a fresh authentication needs the second factor once it is enrolled
+	if usr.TotpEnabledAt != nil {
+		err = s.ms.VerifySecondFactor(usr, in.TotpCode, in.RecoveryCode)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+
+	now := time.Now()
+	if !in.SessionID.IsNil() {
+		err = s.ssr.UpdateAuthTime(ctx, in.SessionID, now)
+		if err != nil {
+			// The session was revoked or expired since the token was issued
+			if errors.Is(err, domain.DataNotFoundError{}) {
+				return result, domain.UnauthorizedError{Code: domain.ErrorCodeUNAUTHORIZED, Message: domain.MessageUNAUTHORIZEDACCESS}
+			}
+			return result, err
+		}
+	}
+	err = s.consumeCode(ctx, loginCode)
+	if err != nil {
+		return result, err
+	}
+	ti := security.TokenMetadata{
+		UserID:   usr.ID.String(),
+		Role:     usr.Role,
+		Mfa:      in.Mfa,
+		AuthTime: now.Unix(),
+	}
+	if !in.SessionID.IsNil() {
+		ti.SessionID = in.SessionID.String()
+	}
+	token, err := s.scm.GenerateAuthToken(ti)
+	if err != nil {
+		return result, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	return domain.StepUpOutput{
+		Token:     token,
+		ExpiresIn: int64(s.cfg.AuthExpiryPeriod),
+		AuthTime:  now.Unix(),
+	}, nil
+}
+
+// RequestPhoneChange implements domain.UserService.
+//
+// The new number gets a code to prove the user owns it. The current number gets one too, unless an admin vouches
+// for the user because the current number is lost.
+func (s *UserService) RequestPhoneChange(in domain.ChangePhoneInput) (result domain.InitLoginOutput, err error) {
+	// Admins cannot vouch for themselves
+	if in.AdminAssisted && in.ID == in.ActorID {
+		return result, domain.ForbiddenAccessError{Code: domain.ErrorCodeFORBIDDEN_ACCESS, Message: domain.MessageNOT_ALLOWED_FOR_OPERATION}
+	}
+	usr, err := s.usr.FindByID(context.Background(), in.ID)
+	if err != nil {
+		return result, err
+	}
+	if in.UserName == usr.UserName {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_REQUEST, Message: domain.MessageSAMEPHONENUMBER}
+	}
+	err = s.checkUserNameAvailable(usr.ID, in.UserName)
+	if err != nil {
+		return result, err
+	}
+	result, err = s.issueCode(usr.UserName, domain.LoginCodePurposePHONE_CHANGE_NEW, &in.UserName, domain.OtpMessage{
+		To:      in.UserName,
+		Channel: domain.NotificationChannelSMS,
+	})
+	if err != nil || in.AdminAssisted {
+		return result, err
+	}
+	return s.issueCode(usr.UserName, domain.LoginCodePurposePHONE_CHANGE_OLD, &in.UserName, domain.OtpMessage{
+		To:      usr.UserName,
+		Channel: domain.NotificationChannelSMS,
+	})
+}
+
+// VerifyPhoneChange implements domain.UserService.
+func (s *UserService) VerifyPhoneChange(in domain.VerifyPhoneChangeInput) (result domain.User, err error) {
+	if in.AdminAssisted && in.ID == in.ActorID {
+		return result, domain.ForbiddenAccessError{Code: domain.ErrorCodeFORBIDDEN_ACCESS, Message: domain.MessageNOT_ALLOWED_FOR_OPERATION}
+	}
+	if !in.AdminAssisted && in.OldOtp == "" {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageINVALIDOTP}
+	}
+	usr, err := s.usr.FindByID(context.Background(), in.ID)
+	if err != nil {
+		return result, err
+	}
+	newCode, err := s.verifyCode(usr.UserName, domain.LoginCodePurposePHONE_CHANGE_NEW, in.NewOtp)
+	if err != nil {
+		return result, err
+	}
+	if newCode.Target == nil {
+		return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageINVALIDOTP}
+	}
+	method := domain.PhoneChangeMethodADMIN
+	var oldCode domain.LoginCode
+	if !in.AdminAssisted {
+		method = domain.PhoneChangeMethodSELF
+		oldCode, err = s.verifyCode(usr.UserName, domain.LoginCodePurposePHONE_CHANGE_OLD, in.OldOtp)
+		if err != nil {
+			return result, err
+		}
+		// Both codes must come from the same request
+		if oldCode.Target == nil || *oldCode.Target != *newCode.Target {
+			return result, domain.UserError{Code: domain.ErrorCodeINVALID_OTP, Message: domain.MessageINVALIDOTP}
+		}
+	}
+	oldUserName, newUserName := usr.UserName, *newCode.Target
+	// Another account may have registered the number since the codes were sent
+	err = s.checkUserNameAvailable(usr.ID, newUserName)
+	if err != nil {
+		return result, err
+	}
+
+	ctx := context.Background()
+	ctx, err = s.tr.Begin(ctx)
+	if err != nil {
+		return result, err
+	}
+	defer func() {
+		s.tr.Rollback(ctx, err)
+	}()
+
+	// Spend the verified codes first, so a replayed request fails before changing anything
+	err = s.consumeCode(ctx, newCode)
+	if err != nil {
+		return result, err
+	}
+	if !oldCode.ID.IsNil() {
+		err = s.consumeCode(ctx, oldCode)
+		if err != nil {
+			return result, err
+		}
+	}
+	usr.UserName = newUserName
+	err = s.usr.UpdateUser(ctx, &usr)
+	if err != nil {
+		return result, err
+	}
+	// Other pending codes are keyed by the old number, so none of them may be used any more
+	for _, purpose := range []domain.LoginCodePurpose{
+		domain.LoginCodePurposeLOGIN,
+		domain.LoginCodePurposeSTEP_UP,
+		domain.LoginCodePurposeEMAIL_VERIFICATION,
+		domain.LoginCodePurposePHONE_CHANGE_OLD,
+		domain.LoginCodePurposePHONE_CHANGE_NEW,
+	} {
+		err = s.lcr.DeleteByUsername(ctx, oldUserName, purpose)
+		if err != nil {
+			return result, err
+		}
+	}
+	// The username is part of the identity, so every session starts over with the new number
+	validAfter, err := s.rs.RevokeAllForUser(ctx, usr.ID, time.Now())
+	if err != nil {
+		return result, err
+	}
+	err = s.ssr.RevokeByUserID(ctx, usr.ID)
+	if err != nil {
+		return result, err
+	}
+	err = s.mlr.ExpireByUserID(ctx, usr.ID)
+	if err != nil {
+		return result, err
+	}
+	audit := domain.PhoneChange{
+		UserID:      usr.ID,
+		OldUserName: oldUserName,
+		NewUserName: newUserName,
+		Method:      method,
+		Reason:      optionalString(in.Reason),
+		IPAddress:   optionalString(in.IPAddress),
+		UserAgent:   optionalString(in.UserAgent),
+		RequestID:   optionalString(in.RequestID),
+	}
+	if !in.ActorID.IsNil() {
+		audit.ActorID = &in.ActorID
+	}
+	err = s.pcr.Create(ctx, &audit)
+	if err != nil {
+		return result, err
+	}
+	err = s.tr.Commit(ctx)
+	if err != nil {
+		return result, err
+	}
+	s.rs.CacheValidAfter(usr.ID, validAfter)
+
+	return s.usr.FindByID(context.Background(), usr.ID)
+}
+
+// checkUserNameAvailable returns an error when the mobile number belongs to another user
+func (s *UserService) checkUserNameAvailable(userID uuid.UUID, username string) (err error) {
+	owner, err := s.usr.FindByUserName(context.Background(), username)
+	if err != nil {
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return nil
+		}
+		return err
+	}
+	if owner.ID != userID {
+		return domain.ConflictError{Code: domain.ErrorCodeUSERNAME_EXISTS, Message: domain.MessageUSERNAMEEREXISTS}
+	}
+	return nil
+}
+
+// checkEmailAvailable returns an error when the email belongs to another user
+func (s *UserService) checkEmailAvailable(userID uuid.UUID, email string) (err error) {
+	owner, err := s.usr.FindByEmail(context.Background(), email)
+	if err != nil {
+		if errors.Is(err, domain.DataNotFoundError{}) {
+			return nil
+		}
+		return err
+	}
+	if owner.ID != userID {
+		return domain.ConflictError{Code: domain.ErrorCodeEMAIL_EXISTS, Message: domain.MessageEMAILEXISTS}
+	}
+	return nil
+}
+
+// rateLimit defines a limit of requests per window in seconds for a key
+type rateLimit struct {
+	key    string
+	limit  int
+	window int
+}
+
+// checkInitLoginLimits applies the rate limits per client IP, per username and, for SMS, per country prefix
+func (s *UserService) checkInitLoginLimits(in domain.InitLoginInput) (err error) {
+	limits := []rateLimit{
+		{key: "init_login:ip:" + in.IPAddress, limit: s.cfg.RateLimitIP, window: s.cfg.RateLimitIPWindow},
+		{key: "init_login:username:" + in.UserName, limit: s.cfg.RateLimitUsername, window: s.cfg.RateLimitUsernameWindow},
+	}
+	// The per country limit protects against SMS pumping, which does not apply to emails
+	if in.Channel != domain.NotificationChannelEMAIL {
+		limits = append(limits, rateLimit{key: "init_login:prefix:" + phone.CountryCode(in.UserName), limit: s.cfg.RateLimitPrefix, window: s.cfg.RateLimitPrefixWindow})
+	}
+	for _, l := range limits {
+		// A limit of zero disables the check
+		if l.limit <= 0 || l.window <= 0 {
+			continue
+		}
+		allowed, retryAfter, err := s.rl.Allow(context.Background(), l.key, l.limit, time.Duration(l.window)*time.Second)
+		if err != nil {
+			return err
+		}
+		if !allowed {
+			return domain.TooManyRequestsError{
+				Code:       domain.ErrorCodeRATE_LIMITED,
+				Message:    domain.MessageRATELIMITED,
+				RetryAfter: int64(math.Ceil(retryAfter.Seconds())),
+			}
+		}
+	}
+	return nil
 }
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid/v5"
+
+	"github.com/weCredit/internal/domain"
+	"github.com/weCredit/internal/pkg/config"
+	"github.com/weCredit/internal/pkg/security"
+)
+
+// fakeLoginCodeRepository keeps the login codes in memory. Consume and IncrementAttempts follow the conditional
+// updates of the postgres repository, the other methods are not needed by the tests.
+type fakeLoginCodeRepository struct {
+	domain.LoginCodeRepository
+
+	mu    sync.Mutex
+	codes map[uuid.UUID]domain.LoginCode
+}
+
+func newFakeLoginCodeRepository(codes ...domain.LoginCode) *fakeLoginCodeRepository {
+	r := &fakeLoginCodeRepository{codes: make(map[uuid.UUID]domain.LoginCode)}
+	for _, lc := range codes {
+		r.codes[lc.ID] = lc
+	}
+	return r
+}
+
+func (r *fakeLoginCodeRepository) FindByUsername(ctx context.Context, username string, purpose domain.LoginCodePurpose) (result domain.LoginCode, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, lc := range r.codes {
+		if lc.Username == username && lc.Purpose == purpose && lc.DeletedAt == nil {
+			return lc, nil
+		}
+	}
+	return result, domain.DataNotFoundError{}
+}
+
+func (r *fakeLoginCodeRepository) Update(ctx context.Context, id uuid.UUID, entity *domain.LoginCode) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codes[id] = *entity
+	return nil
+}
+
+func (r *fakeLoginCodeRepository) IncrementAttempts(ctx context.Context, id uuid.UUID) (attempts int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lc, ok := r.codes[id]
+	if !ok {
+		return 0, domain.DataNotFoundError{}
+	}
+	lc.Attempts++
+	r.codes[id] = lc
+	return lc.Attempts, nil
+}
+
+func (r *fakeLoginCodeRepository) Consume(ctx context.Context, id uuid.UUID) (err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	lc, ok := r.codes[id]
+	if !ok || lc.Status != domain.LoginCodeStatusPENDING || lc.DeletedAt != nil {
+		return domain.DataNotFoundError{}
+	}
+	now := time.Now()
+	lc.Status = domain.LoginCodeStatusSUCCESS
+	lc.DeletedAt = &now
+	r.codes[id] = lc
+	return nil
+}
+
+const (
+	testUsername = "+919876543210"
+	testOtp      = "123456"
+)
+
+// newTestUserService returns a service with a pending login code of testOtp for testUsername
+func newTestUserService(t *testing.T, cfg config.WeCreditConfig) (*UserService, *fakeLoginCodeRepository) {
+	t.Helper()
+	cfg.OtpHashSecret = "test-secret"
+	oh, err := security.NewOtpHasher(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lcr := newFakeLoginCodeRepository(domain.LoginCode{
+		Base:       domain.Base{ID: uuid.Must(uuid.NewV4())},
+		Username:   testUsername,
+		Code:       oh.Hash(testOtp),
+		ExpiryTime: time.Now().Add(time.Minute),
+		Status:     domain.LoginCodeStatusPENDING,
+		Purpose:    domain.LoginCodePurposeLOGIN,
+	})
+	return &UserService{cfg: cfg, lcr: lcr, oh: oh}, lcr
+}
+
+func TestVerifyCodeRefusesEveryWrongOtp(t *testing.T) {
+	// A lockout period of 0 is rejected by the configuration, the service must not rely on it
+	for _, lockout := range []int{15, 0} {
+		s, _ := newTestUserService(t, config.WeCreditConfig{OtpMaxAttempts: 3, OtpLockoutPeriod: lockout})
+		for attempt := 1; attempt <= 3; attempt++ {
+			result, err := s.verifyCode(testUsername, domain.LoginCodePurposeLOGIN, "000000")
+			if err == nil {
+				t.Fatalf("lockout %d, attempt %d: a wrong otp was accepted", lockout, attempt)
+			}
+			if !result.ID.IsNil() {
+				t.Errorf("lockout %d, attempt %d: a login code was returned with the error", lockout, attempt)
+			}
+			var usrErr domain.UserError
+			var tmrErr domain.TooManyRequestsError
+			switch {
+			case attempt < 3 && !(errors.As(err, &usrErr) && usrErr.Code == domain.ErrorCodeINVALID_OTP):
+				t.Errorf("lockout %d, attempt %d: got %v, want INVALID_OTP", lockout, attempt, err)
+			case attempt == 3 && !(errors.As(err, &tmrErr) && tmrErr.Code == domain.ErrorCodeOTP_LOCKED):
+				t.Errorf("lockout %d, attempt %d: got %v, want OTP_LOCKED", lockout, attempt, err)
+			}
+		}
+		// Once the limit is reached even the right otp is refused
+		if _, err := s.verifyCode(testUsername, domain.LoginCodePurposeLOGIN, testOtp); err == nil {
+			t.Errorf("lockout %d: the right otp was accepted after the attempts ran out", lockout)
+		}
+	}
+}
+
+func TestVerifyCodeAcceptsRightOtp(t *testing.T) {
+	s, _ := newTestUserService(t, config.WeCreditConfig{OtpMaxAttempts: 3, OtpLockoutPeriod: 15})
+	result, err := s.verifyCode(testUsername, domain.LoginCodePurposeLOGIN, testOtp)
+	if err != nil {
+		t.Fatalf("got %v, want no error", err)
+	}
+	if result.ID.IsNil() {
+		t.Error("the login code was not returned")
+	}
+}
+
+func TestConsumeCodeSpendsOtpOnce(t *testing.T) {
+	s, _ := newTestUserService(t, config.WeCreditConfig{OtpMaxAttempts: 3, OtpLockoutPeriod: 15})
+	// Every request verifies the code before any of them consumes it, like concurrent logins
+	const requests = 8
+	codes := make([]domain.LoginCode, requests)
+	for i := range codes {
+		lc, err := s.verifyCode(testUsername, domain.LoginCodePurposeLOGIN, testOtp)
+		if err != nil {
+			t.Fatalf("request %d: got %v, want no error", i, err)
+		}
+		codes[i] = lc
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, requests)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = s.consumeCode(context.Background(), codes[i])
+		}(i)
+	}
+	wg.Wait()
+
+	spent := 0
+	for _, err := range errs {
+		var usrErr domain.UserError
+		switch {
+		case err == nil:
+			spent++
+		case !errors.As(err, &usrErr) || usrErr.Code != domain.ErrorCodeINVALID_OTP:
+			t.Errorf("got %v, want INVALID_OTP", err)
+		}
+	}
+	if spent != 1 {
+		t.Errorf("the otp was spent %d times, want once", spent)
+	}
+	// A replay after the code was spent fails before any session is started
+	if _, err := s.verifyCode(testUsername, domain.LoginCodePurposeLOGIN, testOtp); err == nil {
+		t.Error("a spent otp was accepted again")
+	}
+}